@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/config"
+)
+
+var catalogCommands = []struct {
+	name    string
+	summary string
+	run     func(cfg config.Config, args []string) error
+}{
+	{"import", "Add collect and lookup JSON files to a deck", runCatalogImport},
+	{"export", "Write a deck out as collect and lookup JSON files", runCatalogExport},
+	{"show", "Print everything known about a song", runCatalogShow},
+	{"prints", "List the print runs", runCatalogPrints},
+}
+
+func runCatalog(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		catalogUsage()
+		return fmt.Errorf("no catalog command given")
+	}
+	for _, c := range catalogCommands {
+		if c.name == args[0] {
+			return c.run(cfg, args[1:])
+		}
+	}
+	catalogUsage()
+	return fmt.Errorf("unknown catalog command %q", args[0])
+}
+
+func catalogUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: temporalize catalog <command> [flags]\n\nCommands:\n")
+	for _, c := range catalogCommands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// openCatalog registers -catalog, parses the flags of a catalog command
+// and opens the catalogue.
+func openCatalog(cfg config.Config, fs *flag.FlagSet, args []string) (*catalog.Catalog, error) {
+	path := cfg.Paths.Catalog
+	catalogFlag(fs, &path)
+	fs.Parse(args)
+	if path == "" {
+		return nil, fmt.Errorf("no catalogue: set paths.catalog or -catalog")
+	}
+	return catalog.Open(path)
+}
+
+func runCatalogImport(cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog import", "")
+	collectFile := fs.String("collect", cfg.Paths.Collect, "Collect output to add to the deck; empty skips it")
+	lookupFile := fs.String("lookup", cfg.Paths.Lookup, "Lookup summary to store and add to the deck; empty skips it")
+	deck := fs.String("deck", cfg.Deck, "Deck to import into")
+	cat, err := openCatalog(cfg, fs, args)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	collected, lookedUp, err := cat.Import(*deck, *collectFile, *lookupFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d collected and %d looked up songs into deck %s\n", collected, lookedUp, *deck)
+	return nil
+}
+
+func runCatalogExport(cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog export", "")
+	collectFile := fs.String("collect", cfg.Paths.Collect, "Collect output to write the deck's songs to; empty skips it")
+	lookupFile := fs.String("lookup", cfg.Paths.Lookup, "Lookup summary to write the deck's looked up songs to; empty skips it")
+	deck := fs.String("deck", cfg.Deck, "Deck to export")
+	cat, err := openCatalog(cfg, fs, args)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	collected, lookedUp, err := cat.Export(*deck, *collectFile, *lookupFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d collected and %d looked up songs of deck %s\n", collected, lookedUp, *deck)
+	return nil
+}
+
+func runCatalogShow(cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog show", " <spotify url or id>...")
+	cat, err := openCatalog(cfg, fs, args)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no song given")
+	}
+	prints, err := cat.Prints()
+	if err != nil {
+		return err
+	}
+
+	for i, arg := range fs.Args() {
+		id := catalog.SongID(arg)
+		if id == "" {
+			return fmt.Errorf("%s is not a Spotify track", arg)
+		}
+		s, decks, err := cat.Song(id)
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			return fmt.Errorf("song %s is not in the catalogue", id)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Spotify:   %s\n", s.ID)
+		if g := s.Summary; g != nil {
+			fmt.Printf("Song:      %s by %s (%d)\n", g.Title, strings.Join(g.Artists, ", "), g.Year)
+			fmt.Printf("Looked up: %s\n", s.LookedUp.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("Decks:     %s\n", strings.Join(decks, ", "))
+		if t := s.Thumbnail; t != nil {
+			fmt.Printf("Thumbnail: %s (%dx%d, %d bytes)\n", t.File, t.Width, t.Height, t.Bytes)
+		}
+		if len(s.History) > 0 {
+			fmt.Println("History:")
+		}
+		for _, v := range s.History {
+			fmt.Printf("  %s %-13s %-10s %s\n", v.At.Format("2006-01-02 15:04"), v.Platform, v.Status, v.Link)
+		}
+		for _, p := range prints {
+			for _, printed := range p.Songs {
+				if printed == id {
+					fmt.Printf("Printed:   run %d of deck %s on %s in %s\n", p.ID, p.Deck, p.At.Format("2006-01-02"), p.Output)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func runCatalogPrints(cfg config.Config, args []string) error {
+	fs := newFlagSet("catalog prints", "")
+	cat, err := openCatalog(cfg, fs, args)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+
+	prints, err := cat.Prints()
+	if err != nil {
+		return err
+	}
+	for _, p := range prints {
+		fmt.Printf("%4d  %s  %-16s %4d %s cards in %s\n", p.ID, p.At.Format("2006-01-02 15:04"), p.Deck, len(p.Songs), p.Format, p.Output)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"temporalize/internal/collect"
+	"temporalize/internal/config"
+)
+
+func runCollect(cfg config.Config, args []string) error {
+	opts, err := collect.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("collect", "")
+	fs.StringVar(&opts.OutputFile, "output", opts.OutputFile, "Output JSON file")
+	yearFlags(fs, &opts.StartYear, &opts.EndYear)
+	catalogFlag(fs, &opts.Catalog)
+	loadEndpoints := endpointsFlag(fs)
+	fs.Parse(args)
+
+	if opts.Upstream, err = loadEndpoints(); err != nil {
+		return err
+	}
+	return collect.Run(opts)
+}
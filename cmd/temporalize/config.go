@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"temporalize/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func runConfig(cfg config.Config, args []string) error {
+	fs := newFlagSet("config", "")
+	fs.Parse(args)
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	return enc.Close()
+}
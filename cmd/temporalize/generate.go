@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+
+	"temporalize/internal/config"
+	"temporalize/internal/generate"
+)
+
+func runGenerate(cfg config.Config, args []string) error {
+	opts, err := generate.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("generate", "")
+	fs.StringVar(&opts.InputFile, "input", opts.InputFile, "Path to input JSON file")
+	fs.StringVar(&opts.OutputDir, "output", opts.OutputDir, "Output directory for generated assets")
+	fs.StringVar(&opts.OverridesFile, "overrides", opts.OverridesFile, "JSON file of per-song field overrides keyed by Spotify ID")
+	catalogFlag(fs, &opts.Catalog)
+	generateFlags(fs, &opts)
+	fs.Parse(args)
+
+	return generate.Run(opts)
+}
+
+// generateFlags registers the generate flags that pipeline shares.
+func generateFlags(fs *flag.FlagSet, opts *generate.Options) {
+	fs.StringVar(&opts.Require, "require", opts.Require, `Platforms a card needs, e.g. "spotify+2" for Spotify plus two others, or "all"`)
+	fs.BoolVar(&opts.AllowUnverified, "allow-unverified", opts.AllowUnverified, "Count links that lookup could not check towards -require")
+	fs.StringVar(&opts.Card.Format, "format", opts.Card.Format, "Card file format: png, or svg or pdf for vector cards with embedded fonts")
+	fs.StringVar(&opts.Card.QR.Level, "qr-level", opts.Card.QR.Level, "QR error correction level: L, M, Q or H")
+	fs.StringVar(&opts.Sheets, "sheets", opts.Sheets, "Also impose the cards onto print-ready PDF sheets of this paper (letter or a4)")
+	fs.StringVar(&opts.Duplex, "duplex", opts.Duplex, "Edge the sheets are flipped on when printed duplex: long or short")
+	fs.BoolVar(&opts.Registration, "registration", opts.Registration, "Start the sheets with a duplex registration test sheet")
+	fs.BoolVar(&opts.Verify, "verify", opts.Verify, "Scan every card back and fail the cards whose QR code does not decode to their links")
+}
+
+func runVerify(cfg config.Config, args []string) error {
+	opts, err := generate.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("verify", "")
+	fs.StringVar(&opts.InputFile, "input", opts.InputFile, "Lookup summary the cards were generated from")
+	fs.StringVar(&opts.OutputDir, "output", opts.OutputDir, "Output directory of generated assets to verify")
+	fs.StringVar(&opts.OverridesFile, "overrides", opts.OverridesFile, "JSON file of per-song field overrides keyed by Spotify ID")
+	fs.StringVar(&opts.Require, "require", opts.Require, "Platform policy the cards were generated with; only its links are expected on the backs")
+	fs.BoolVar(&opts.AllowUnverified, "allow-unverified", opts.AllowUnverified, "Expect the links lookup could not check on the backs, as generate -allow-unverified does")
+	catalogFlag(fs, &opts.Catalog)
+	fs.Parse(args)
+
+	return generate.Verify(opts)
+}
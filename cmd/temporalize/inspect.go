@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/codec"
+	"temporalize/internal/config"
+	"temporalize/internal/platform"
+)
+
+func runInspect(cfg config.Config, args []string) error {
+	fs := newFlagSet("inspect", " <payload>...")
+	format := fs.String("format", "hex", "Encoding of the payload arguments: hex or base64")
+	catalogPath := cfg.Paths.Catalog
+	catalogFlag(fs, &catalogPath)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no payload given")
+	}
+
+	// The catalogue names the song on each card.
+	var cat *catalog.Catalog
+	if catalogPath != "" {
+		var err error
+		if cat, err = catalog.Open(catalogPath); err != nil {
+			return err
+		}
+		defer cat.Close()
+	}
+
+	for i, arg := range fs.Args() {
+		data, err := decodePayloadArg(arg, *format)
+		if err != nil {
+			return err
+		}
+		var p codec.CardPayload
+		if err := p.Decode(data); err != nil {
+			return fmt.Errorf("payload %d: %w", i+1, err)
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Payload:  %x (%d bytes)\n", data, len(data))
+		fmt.Printf("Version:  %d\n", p.Version)
+		fmt.Printf("Explicit: %v\n", p.Explicit)
+		if cat != nil && p.Spotify != "" {
+			s, _, err := cat.Song(p.Spotify)
+			if err != nil {
+				return err
+			}
+			if s != nil && s.Summary != nil {
+				fmt.Printf("Song:     %s by %s (%d)\n", s.Summary.Title, strings.Join(s.Summary.Artists, ", "), s.Summary.Year)
+			}
+		}
+		for _, pl := range platform.All() {
+			id := pl.Decode(p)
+			if id == "" {
+				fmt.Printf("%-14s -\n", pl.Name()+":")
+				continue
+			}
+			fmt.Printf("%-14s %s %s\n", pl.Name()+":", id, pl.URL(id))
+		}
+	}
+	return nil
+}
+
+func decodePayloadArg(arg, format string) ([]byte, error) {
+	switch format {
+	case "hex":
+		return hex.DecodeString(strings.TrimSpace(arg))
+	case "base64":
+		return codec.DecodeBase64(arg)
+	}
+	return nil, fmt.Errorf("unknown payload format %q, want hex or base64", format)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"temporalize/internal/config"
+	"temporalize/internal/lookup"
+	"temporalize/internal/platform"
+)
+
+func runLookup(cfg config.Config, args []string) error {
+	opts, err := lookup.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet("lookup", "")
+	fs.StringVar(&opts.InputFile, "input", opts.InputFile, "Path to input JSON file")
+	fs.StringVar(&opts.SummaryFile, "summary", opts.SummaryFile, "Output JSON file for generated songs summary")
+	yearFlags(fs, &opts.StartYear, &opts.EndYear)
+	fs.StringVar(&opts.OverridesFile, "overrides", opts.OverridesFile, "JSON file of per-song field overrides keyed by Spotify ID")
+	lookupFlags(fs, &opts)
+	catalogFlag(fs, &opts.Catalog)
+	loadEndpoints := endpointsFlag(fs)
+	fs.Parse(args)
+
+	if opts.Upstream, err = loadEndpoints(); err != nil {
+		return err
+	}
+	return lookup.Run(opts)
+}
+
+// lookupFlags registers the lookup flags that pipeline shares.
+func lookupFlags(fs *flag.FlagSet, opts *lookup.Options) {
+	fs.StringVar(&opts.Platforms, "platforms", opts.Platforms, "Comma separated platforms to look up (default all: "+strings.Join(platform.Keys(), ",")+")")
+	fs.StringVar(&opts.CacheDir, "cache-dir", opts.CacheDir, "Directory for cached HTTP responses (empty disables the cache)")
+	fs.StringVar(&opts.CacheTTL, "cache-ttl", opts.CacheTTL, "Comma separated per-host cache lifetimes overriding the defaults, e.g. api.song.link=720h,www.youtube.com=0")
+	fs.BoolVar(&opts.Offline, "offline", opts.Offline, "Serve every request from the cache and fail on a miss")
+	fs.BoolVar(&opts.Resume, "resume", opts.Resume, "Keep songs already completed in the summary file and only look up the rest")
+	fs.IntVar(&opts.Concurrency, "concurrency", opts.Concurrency, "Number of songs looked up in parallel")
+	fs.BoolVar(&opts.MusicBrainz, "musicbrainz", opts.MusicBrainz, "Resolve each song's original release year on MusicBrainz by ISRC")
+	fs.StringVar(&opts.MusicBrainzURL, "musicbrainz-url", opts.MusicBrainzURL, "MusicBrainz web service root (default from -endpoints)")
+	fs.IntVar(&opts.YearTolerance, "year-tolerance", opts.YearTolerance, "Flag songs whose Spotify and original years differ by more than this many years")
+	fs.Float64Var(&opts.MatchThreshold, "match-threshold", opts.MatchThreshold, "Lowest title/artist similarity score (0-1) accepted when a platform has no ISRC")
+	fs.StringVar(&opts.ReportFile, "report", opts.ReportFile, "Write a self-contained HTML report for reviewing the run to this file")
+	fs.StringVar(&opts.RateLimits, "rate-limits", opts.RateLimits, "Comma separated per-host request rates overriding the defaults, as host=rate[:burst] in requests per second, e.g. api.song.link=0.5:1")
+}
@@ -0,0 +1,99 @@
+// Command temporalize builds and serves the Temporalize card game: it
+// collects songs from Spotify, looks up their links on every platform,
+// prints the cards and serves the scanner.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"temporalize/internal/config"
+	"temporalize/internal/endpoints"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(cfg config.Config, args []string) error
+}
+
+var commands = []command{
+	{"collect", "Collect top Spotify songs by popularity", runCollect},
+	{"lookup", "Look up and fix links for collected songs", runLookup},
+	{"generate", "Generate card assets from looked up songs", runGenerate},
+	{"verify", "Scan generated card backs and check their QR codes", runVerify},
+	{"pipeline", "Run collect, lookup and generate end to end", runPipeline},
+	{"inspect", "Decode QR payloads and print their links", runInspect},
+	{"catalog", "Import, export and query the card catalogue", runCatalog},
+	{"serve", "Serve the web app", runServe},
+	{"config", "Print the effective configuration as YAML", runConfig},
+}
+
+func main() {
+	flag.Usage = usage
+	configFile := flag.String("config", "", "YAML config file (default "+config.DefaultPath+" if it exists)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := flag.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		log.SetPrefix(name + ": ")
+		log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+		cfg, err := config.Load(*configFile)
+		if err != nil {
+			log.Fatalf("Error: config: %v", err)
+		}
+		if err := c.run(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "temporalize: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: temporalize [-config file] <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"temporalize <command> -h\" for a command's flags.\n")
+}
+
+// newFlagSet returns the flag set for a subcommand, with usage naming it.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: temporalize %s [flags]%s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// endpointsFlag registers -endpoints and returns a loader for its value.
+func endpointsFlag(fs *flag.FlagSet) func() (endpoints.Endpoints, error) {
+	path := fs.String("endpoints", "", "JSON file overriding upstream service URLs, e.g. to run against fakes")
+	return func() (endpoints.Endpoints, error) {
+		return endpoints.Load(*path)
+	}
+}
+
+// catalogFlag registers -catalog, defaulting to the configured catalogue.
+func catalogFlag(fs *flag.FlagSet, path *string) {
+	fs.StringVar(path, "catalog", *path, "Catalogue database to use instead of the collect and lookup JSON files (default paths.catalog)")
+}
+
+// yearFlags registers -start and -end, defaulting to the configured years.
+func yearFlags(fs *flag.FlagSet, start, end *int) {
+	fs.IntVar(start, "start", *start, "Start year (inclusive)")
+	fs.IntVar(end, "end", *end, "End year (inclusive)")
+}
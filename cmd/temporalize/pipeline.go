@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"temporalize/internal/collect"
+	"temporalize/internal/config"
+	"temporalize/internal/generate"
+	"temporalize/internal/lookup"
+)
+
+var stages = []string{"collect", "lookup", "generate"}
+
+func runPipeline(cfg config.Config, args []string) error {
+	collectOpts, err := collect.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	lookupOpts, err := lookup.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+	generateOpts, err := generate.NewOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("pipeline", "")
+	dir := fs.String("dir", "", "Directory for the intermediate collect.json and lookup.json (default the configured paths)")
+	from := fs.String("from", stages[0], "First stage to run; later stages reuse the intermediate files of earlier ones")
+	fs.StringVar(&generateOpts.OutputDir, "output", generateOpts.OutputDir, "Output directory for generated assets")
+	yearFlags(fs, &collectOpts.StartYear, &collectOpts.EndYear)
+	overridesFile := fs.String("overrides", lookupOpts.OverridesFile, "JSON file of per-song field overrides keyed by Spotify ID")
+	catalogFlag(fs, &collectOpts.Catalog)
+	lookupFlags(fs, &lookupOpts)
+	generateFlags(fs, &generateOpts)
+	loadEndpoints := endpointsFlag(fs)
+	fs.Parse(args)
+
+	first := -1
+	for i, s := range stages {
+		if s == *from {
+			first = i
+		}
+	}
+	if first < 0 {
+		return fmt.Errorf("unknown stage %q, want one of %v", *from, stages)
+	}
+
+	upstream, err := loadEndpoints()
+	if err != nil {
+		return err
+	}
+	if *dir != "" {
+		collectOpts.OutputFile = filepath.Join(*dir, "collect.json")
+		lookupOpts.SummaryFile = filepath.Join(*dir, "lookup.json")
+	}
+	collectOpts.Upstream = upstream
+
+	lookupOpts.InputFile = collectOpts.OutputFile
+	lookupOpts.StartYear, lookupOpts.EndYear = collectOpts.StartYear, collectOpts.EndYear
+	lookupOpts.OverridesFile = *overridesFile
+	lookupOpts.Upstream = upstream
+	lookupOpts.Catalog = collectOpts.Catalog
+
+	generateOpts.InputFile = lookupOpts.SummaryFile
+	generateOpts.OverridesFile = *overridesFile
+	generateOpts.Catalog = collectOpts.Catalog
+
+	run := []func() error{
+		func() error { return collect.Run(collectOpts) },
+		func() error { return lookup.Run(lookupOpts) },
+		func() error { return generate.Run(generateOpts) },
+	}
+	for i := first; i < len(stages); i++ {
+		fmt.Printf("== %s ==\n", stages[i])
+		if err := run[i](); err != nil {
+			return fmt.Errorf("%s: %w", stages[i], err)
+		}
+	}
+	return nil
+}
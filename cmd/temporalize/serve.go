@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"temporalize/internal/config"
+	"temporalize/internal/server"
+)
+
+func runServe(_ config.Config, args []string) error {
+	fs := newFlagSet("serve", "")
+	var opts server.Options
+	dev, _ := strconv.ParseBool(os.Getenv("DEV_MODE"))
+	fs.StringVar(&opts.Addr, "addr", ":8000", "Address to listen on")
+	fs.StringVar(&opts.Dir, "dir", "", "Serve the web app from this directory instead of the copy built into the binary")
+	fs.BoolVar(&opts.Dev, "dev", dev, "Serve HTTPS with a self-signed certificate (default from DEV_MODE)")
+	fs.StringVar(&opts.CertDir, "cert-dir", ".cache/serve", "Directory the self-signed certificate is kept in")
+	fs.StringVar(&opts.CertFile, "cert", os.Getenv("TLS_PEM_PATH"), "TLS certificate file (default from TLS_PEM_PATH); serves plain HTTP when empty")
+	fs.StringVar(&opts.KeyFile, "key", os.Getenv("TLS_KEY_PATH"), "TLS private key file (default from TLS_KEY_PATH)")
+	fs.Parse(args)
+
+	return server.Run(opts)
+}
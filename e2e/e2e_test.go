@@ -0,0 +1,350 @@
+// Package e2e runs the temporalize commands against the fakes in
+// internal/fakes, with the network otherwise unreachable.
+package e2e
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/fakes"
+	"temporalize/internal/models"
+)
+
+func TestCollectLookupGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every command")
+	}
+
+	srv := fakes.New()
+	defer srv.Close()
+	work, run := setup(t, srv)
+
+	// Collect keeps the three popular 1985 tracks.
+	run("collect", "-output", "collect.json", "-start", "1985", "-end", "1985", "-endpoints", "endpoints.json")
+	var collected []struct {
+		URL   string `json:"url"`
+		Genre string `json:"genre"`
+		Year  int    `json:"year"`
+	}
+	readJSON(t, filepath.Join(work, "collect.json"), &collected)
+	genres := make(map[string]string)
+	for _, c := range collected {
+		genres[c.URL] = c.Genre
+	}
+	wantGenres := map[string]string{
+		"https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L": "pop",
+		"https://open.spotify.com/track/3fH4KjXFYMmljxrcGrbPj9": "rock",
+		"https://open.spotify.com/track/4yQw7FR9lcvL6RHtegbJBh": "rock",
+	}
+	if len(genres) != len(wantGenres) {
+		t.Errorf("collected %v, want %v", genres, wantGenres)
+	}
+	for url, want := range wantGenres {
+		if genres[url] != want {
+			t.Errorf("collected genre of %s = %q, want %q", url, genres[url], want)
+		}
+	}
+
+	run("lookup", "-input", "collect.json", "-summary", "lookup.json", "-start", "1985", "-end", "1985",
+		"-cache-dir", "", "-endpoints", "endpoints.json", "-report", "report.html", "-concurrency", "2")
+	var songs []models.GeneratedSong
+	readJSON(t, filepath.Join(work, "lookup.json"), &songs)
+	byTitle := make(map[string]models.GeneratedSong)
+	for _, s := range songs {
+		byTitle[s.Title] = s
+	}
+
+	wantStatuses := map[string]map[string]string{
+		"Take On Me": {
+			"spotify": "ok/source", "appleMusic": "ok/isrc", "amazonMusic": "ok/odesli",
+			"youtubeMusic": "ok/odesli", "deezer": "ok/isrc", "tidal": "ok/odesli",
+		},
+		"Don't You (Forget About Me)": {
+			"spotify": "ok/source", "appleMusic": "fixed/isrc", "amazonMusic": "ok/odesli",
+			"youtubeMusic": "fixed/search", "deezer": "ok/isrc", "tidal": "failed/",
+		},
+		"Money for Nothing": {
+			"spotify": "ok/source", "appleMusic": "failed/", "amazonMusic": "failed/",
+			"youtubeMusic": "failed/", "deezer": "failed/", "tidal": "failed/",
+		},
+	}
+	if len(songs) != len(wantStatuses) {
+		t.Fatalf("lookup wrote %d songs, want %d", len(songs), len(wantStatuses))
+	}
+	for title, want := range wantStatuses {
+		s, ok := byTitle[title]
+		if !ok {
+			t.Errorf("lookup.json has no %q", title)
+			continue
+		}
+		for key, status := range want {
+			p := s.Platforms[key]
+			if got := p.Status + "/" + p.Method; got != status {
+				t.Errorf("%s %s = %s (%s), want %s", title, key, got, p.Reason, status)
+			}
+		}
+	}
+
+	dontYou := byTitle["Don't You (Forget About Me)"]
+	if dontYou.OriginalTitle != "Don't You (Forget About Me) - Remastered 2003" {
+		t.Errorf("original title = %q", dontYou.OriginalTitle)
+	}
+	if want := "https://music.apple.com/us/album/_/1440832700?i=1440832755"; dontYou.AppleMusic != want {
+		t.Errorf("fixed Apple Music link = %q, want %q", dontYou.AppleMusic, want)
+	}
+	if want := "https://music.youtube.com/watch?v=CdqoNKCCt7A"; dontYou.YoutubeMusic != want {
+		t.Errorf("fixed YouTube Music link = %q, want %q", dontYou.YoutubeMusic, want)
+	}
+	if !dontYou.Invalid || dontYou.Tidal != "" {
+		t.Errorf("failed Tidal link: invalid = %v, link = %q", dontYou.Invalid, dontYou.Tidal)
+	}
+
+	report, err := os.ReadFile(filepath.Join(work, "report.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "Money for Nothing") {
+		t.Error("report does not list Money for Nothing")
+	}
+
+	// The default policy prints the first two; Money for Nothing only has
+	// Spotify.
+	run("generate", "-input", "lookup.json", "-output", "out", "-sheets", "letter")
+	for _, s := range songs {
+		song := models.Song{Title: s.Title, Year: s.Year}
+		printed := s.Title != "Money for Nothing"
+		front, back := song.FileName()+"-"+s.Genre+".png", song.FileName()+".png"
+		for _, path := range []string{"cards/front/standard/" + front, "cards/front/usmini/" + front, "cards/back/standard/" + back, "cards/back/usmini/" + back} {
+			path = filepath.Join(work, "out", path)
+			_, err := os.Stat(path)
+			if printed && err != nil {
+				t.Errorf("%s: %v", s.Title, err)
+			}
+			if !printed && err == nil {
+				t.Errorf("%s printed to %s despite the policy", s.Title, path)
+			}
+		}
+	}
+
+	f, err := os.Open(filepath.Join(work, "out/cards/back/standard", (&models.Song{Title: "Take On Me", Year: 1985}).FileName()+".png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2.5" x 3.5" plus an eighth of an inch bleed on each side at 300 dpi.
+	if cfg.Width != 825 || cfg.Height != 1125 {
+		t.Errorf("card back is %dx%d, want 825x1125", cfg.Width, cfg.Height)
+	}
+
+	for _, size := range []string{"standard", "usmini"} {
+		sheet, err := os.ReadFile(filepath.Join(work, "out/sheets", size+".pdf"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// One sheet: the fronts of both printed cards, then their backs.
+		if !bytes.HasPrefix(sheet, []byte("%PDF-")) || !bytes.Contains(sheet, []byte("/Count 2")) {
+			t.Errorf("%s sheets are not a two page PDF", size)
+		}
+	}
+
+	// Vector cards embed their fonts.
+	run("generate", "-input", "lookup.json", "-output", "vector", "-format", "pdf")
+	card, err := os.ReadFile(filepath.Join(work, "vector/cards/back/standard", (&models.Song{Title: "Take On Me", Year: 1985}).FileName()+".pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(card, []byte("%PDF-")) || !bytes.Contains(card, []byte("/FontFile2")) {
+		t.Error("vector card back is not a PDF with an embedded font")
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every command")
+	}
+
+	srv := fakes.New()
+	defer srv.Close()
+	work, run := setup(t, srv)
+
+	out := run("pipeline", "-dir", "build", "-output", "out", "-start", "1985", "-end", "1985",
+		"-cache-dir", "", "-endpoints", "endpoints.json")
+	for _, stage := range []string{"collect", "lookup", "generate"} {
+		if !strings.Contains(out, "== "+stage+" ==") {
+			t.Errorf("pipeline did not run %s", stage)
+		}
+	}
+
+	var songs []models.GeneratedSong
+	readJSON(t, filepath.Join(work, "build", "lookup.json"), &songs)
+	if len(songs) != 3 {
+		t.Errorf("pipeline looked up %d songs, want 3", len(songs))
+	}
+	back := (&models.Song{Title: "Take On Me", Year: 1985}).FileName() + ".png"
+	if _, err := os.Stat(filepath.Join(work, "out/cards/back/standard", back)); err != nil {
+		t.Error(err)
+	}
+
+	// Regenerating reuses the intermediate files.
+	if err := os.RemoveAll(filepath.Join(work, "out")); err != nil {
+		t.Fatal(err)
+	}
+	out = run("pipeline", "-dir", "build", "-output", "out", "-from", "generate")
+	if strings.Contains(out, "== collect ==") {
+		t.Error("pipeline -from generate ran collect")
+	}
+	if _, err := os.Stat(filepath.Join(work, "out/cards/back/standard", back)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every command")
+	}
+
+	srv := fakes.New()
+	defer srv.Close()
+	work, run := setup(t, srv)
+
+	// Every stage reads and writes the catalogue instead of the JSON files.
+	run("pipeline", "-catalog", "catalog.db", "-dir", "build", "-output", "out", "-start", "1985", "-end", "1985",
+		"-cache-dir", "", "-endpoints", "endpoints.json")
+	if _, err := os.Stat(filepath.Join(work, "build")); !os.IsNotExist(err) {
+		t.Errorf("pipeline with a catalogue wrote intermediate files: %v", err)
+	}
+	out := run("catalog", "prints", "-catalog", "catalog.db")
+	// Money for Nothing lacks the links the default policy requires.
+	if !strings.Contains(out, "default") || !strings.Contains(out, "2 png cards in out") {
+		t.Errorf("catalog prints = %q, want a run of 2 default cards", out)
+	}
+
+	run("catalog", "export", "-catalog", "catalog.db", "-collect", "collect.json", "-lookup", "lookup.json")
+	var songs []models.GeneratedSong
+	readJSON(t, filepath.Join(work, "lookup.json"), &songs)
+	if len(songs) != 3 {
+		t.Fatalf("exported %d looked up songs, want 3", len(songs))
+	}
+
+	// The exported files drive the JSON pipeline, and import back into
+	// another deck.
+	run("generate", "-input", "lookup.json", "-output", "from-json")
+	run("catalog", "import", "-catalog", "catalog.db", "-deck", "copy", "-collect", "collect.json", "-lookup", "lookup.json")
+	out = run("catalog", "show", "-catalog", "catalog.db", songs[0].Spotify)
+	for _, want := range []string{"Song:      " + songs[0].Title, "Decks:     copy, default", "History:", "Printed:   run 1 of deck default"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("catalog show lacks %q", want)
+		}
+	}
+
+	// Inspect names the song on a card.
+	data, err := codec.CardPayload{Spotify: strings.TrimPrefix(songs[0].Spotify, "https://open.spotify.com/track/")}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = run("inspect", "-catalog", "catalog.db", hex.EncodeToString(data))
+	if !strings.Contains(out, "Song:     "+songs[0].Title) {
+		t.Errorf("inspect with a catalogue does not name %q", songs[0].Title)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every command")
+	}
+
+	srv := fakes.New()
+	defer srv.Close()
+	_, run := setup(t, srv)
+
+	payload := codec.CardPayload{Spotify: "2WfaOiMkCvy7F5fcp2zZ8L", Deezer: "664107", Explicit: true}
+	data, err := payload.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := run("inspect", hex.EncodeToString(data))
+	for _, want := range []string{
+		"Explicit: true",
+		"https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+		"https://www.deezer.com/track/664107",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("inspect output lacks %q", want)
+		}
+	}
+}
+
+// setup builds temporalize into a work directory holding the assets and the
+// fakes' endpoints, and returns a function running it there.
+func setup(t *testing.T, srv *fakes.Server) (string, func(args ...string) string) {
+	t.Helper()
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	bin := filepath.Join(work, "bin", "temporalize")
+	build := exec.Command("go", "build", "-o", bin, "./cmd/temporalize")
+	build.Dir = root
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("building temporalize: %v\n%s", err, out)
+	}
+	if err := os.Symlink(filepath.Join(root, "assets"), filepath.Join(work, "assets")); err != nil {
+		t.Fatal(err)
+	}
+	writeJSON(t, filepath.Join(work, "endpoints.json"), srv.Endpoints())
+
+	return work, func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(bin, args...)
+		cmd.Dir = work
+		cmd.Env = append(os.Environ(),
+			"SPOTIFY_CLIENT_ID=fake-id",
+			"SPOTIFY_CLIENT_SECRET=fake-secret",
+			// Anything not pointed at the fake fails instead of going out.
+			"HTTP_PROXY=http://127.0.0.1:1",
+			"HTTPS_PROXY=http://127.0.0.1:1",
+			"NO_PROXY=",
+		)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", args[0], err, out)
+		}
+		t.Logf("%s:\n%s", args[0], out)
+		return string(out)
+	}
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+}
@@ -0,0 +1,370 @@
+// Package catalog is the card catalogue: a single-file database of the
+// songs collected into each deck, their latest lookup with the history of
+// every platform link's validation, their album art and the print runs they
+// appeared in. Commands given a catalogue read and write it instead of the
+// collect and lookup JSON files, which Import and Export convert.
+package catalog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+)
+
+var (
+	// songsBucket maps Spotify IDs to Songs.
+	songsBucket = []byte("songs")
+	// decksBucket holds a bucket per deck mapping Spotify IDs to members.
+	decksBucket = []byte("decks")
+	// printsBucket maps big-endian print IDs to Prints.
+	printsBucket = []byte("prints")
+)
+
+// now is replaced by tests.
+var now = time.Now
+
+var spotifyPlatform, _ = platform.Lookup(models.PlatformSpotify)
+
+// Catalog is an open catalogue. One command at a time can have it open.
+type Catalog struct {
+	db *bolt.DB
+}
+
+// Song is what the catalogue holds on a song, keyed by Spotify ID.
+type Song struct {
+	ID string `json:"id"`
+	// Summary is the latest lookup of the song, nil until it is looked up.
+	Summary  *models.GeneratedSong `json:"summary,omitempty"`
+	LookedUp time.Time             `json:"looked_up"`
+	// History has an entry for every change of a platform's link or its
+	// validation outcome, oldest first.
+	History   []Validation `json:"history,omitempty"`
+	Thumbnail *Thumbnail   `json:"thumbnail,omitempty"`
+}
+
+// Validation is a platform link and its validation outcome as of a lookup.
+type Validation struct {
+	At       time.Time `json:"at"`
+	Platform string    `json:"platform"`
+	Link     string    `json:"link"`
+	models.PlatformStatus
+}
+
+// Thumbnail describes the album art downloaded for a song.
+type Thumbnail struct {
+	URL    string `json:"url"`
+	File   string `json:"file"`
+	Bytes  int64  `json:"bytes"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Print is a generate run: the cards of a deck written to a directory.
+type Print struct {
+	ID     uint64    `json:"id"`
+	Deck   string    `json:"deck"`
+	At     time.Time `json:"at"`
+	Output string    `json:"output"`
+	Format string    `json:"format"`
+	// Songs are the Spotify IDs of the songs printed, in card order.
+	Songs []string `json:"songs"`
+}
+
+// member is a song's place in a deck, in collect order.
+type member struct {
+	Seq uint64 `json:"seq"`
+	models.CollectedSong
+}
+
+// Open opens the catalogue at path, creating it if it does not exist.
+func Open(path string) (*Catalog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if errors.Is(err, berrors.ErrTimeout) {
+		return nil, fmt.Errorf("catalogue %s is open in another command", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening catalogue %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{songsBucket, decksBucket, printsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Catalog{db: db}, nil
+}
+
+// Close closes the catalogue file.
+func (c *Catalog) Close() error {
+	return c.db.Close()
+}
+
+// SongID returns the catalogue key of a Spotify track URL or ID, or "" if
+// it is neither.
+func SongID(spotify string) string {
+	return spotifyPlatform.ParseID(spotify)
+}
+
+// ClearDeck removes every song from a deck, before it is collected anew.
+// The songs themselves stay in the catalogue.
+func (c *Catalog) ClearDeck(deck string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(decksBucket).DeleteBucket([]byte(deck))
+		if errors.Is(err, berrors.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// Collect appends songs to a deck, leaving those already in it in place.
+func (c *Catalog) Collect(deck string, songs []models.CollectedSong) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return collect(tx, deck, songs)
+	})
+}
+
+func collect(tx *bolt.Tx, deck string, songs []models.CollectedSong) error {
+	b, err := tx.Bucket(decksBucket).CreateBucketIfNotExists([]byte(deck))
+	if err != nil {
+		return err
+	}
+	for _, s := range songs {
+		id := SongID(s.URL)
+		if id == "" {
+			return fmt.Errorf("%s is not a Spotify track", s.URL)
+		}
+		if b.Get([]byte(id)) != nil {
+			continue
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := put(b, id, member{Seq: seq, CollectedSong: s}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Collected returns the songs of a deck in the order they were collected.
+func (c *Catalog) Collected(deck string) ([]models.CollectedSong, error) {
+	var songs []models.CollectedSong
+	err := c.db.View(func(tx *bolt.Tx) error {
+		members, err := deckMembers(tx, deck)
+		for _, m := range members {
+			songs = append(songs, m.CollectedSong)
+		}
+		return err
+	})
+	return songs, err
+}
+
+func deckMembers(tx *bolt.Tx, deck string) ([]member, error) {
+	b := tx.Bucket(decksBucket).Bucket([]byte(deck))
+	if b == nil {
+		return nil, nil
+	}
+	var members []member
+	err := b.ForEach(func(k, v []byte) error {
+		var m member
+		if err := json.Unmarshal(v, &m); err != nil {
+			return fmt.Errorf("deck %s song %s: %w", deck, k, err)
+		}
+		members = append(members, m)
+		return nil
+	})
+	sort.Slice(members, func(i, j int) bool { return members[i].Seq < members[j].Seq })
+	return members, err
+}
+
+// PutSummary stores the latest lookup of a song, adding the platform links
+// whose link or validation outcome changed since the last one to its
+// history.
+func (c *Catalog) PutSummary(g models.GeneratedSong) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return putSummary(tx, g)
+	})
+}
+
+func putSummary(tx *bolt.Tx, g models.GeneratedSong) error {
+	id := SongID(g.Spotify)
+	if id == "" {
+		return fmt.Errorf("%q has no Spotify track", g.Title)
+	}
+	return updateSong(tx, id, func(s *Song) {
+		at := now()
+		s.Summary, s.LookedUp = &g, at
+		for _, p := range platform.All() {
+			v := Validation{At: at, Platform: p.Key(), Link: g.Link(p.Key()), PlatformStatus: g.Platforms[p.Key()]}
+			if v.Link == "" && v.Status == "" {
+				continue
+			}
+			if last := s.lastValidation(p.Key()); last != nil && last.Link == v.Link && last.PlatformStatus == v.PlatformStatus {
+				continue
+			}
+			s.History = append(s.History, v)
+		}
+	})
+}
+
+func (s *Song) lastValidation(key string) *Validation {
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].Platform == key {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
+// PutThumbnail records the album art downloaded from url to file for the
+// song with the given Spotify ID.
+func (c *Catalog) PutThumbnail(id, url, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	t := &Thumbnail{URL: url, File: file, Bytes: info.Size()}
+	if cfg, _, err := image.DecodeConfig(f); err == nil {
+		t.Width, t.Height = cfg.Width, cfg.Height
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return updateSong(tx, id, func(s *Song) { s.Thumbnail = t })
+	})
+}
+
+func updateSong(tx *bolt.Tx, id string, update func(*Song)) error {
+	b := tx.Bucket(songsBucket)
+	s := Song{ID: id}
+	if v := b.Get([]byte(id)); v != nil {
+		if err := json.Unmarshal(v, &s); err != nil {
+			return fmt.Errorf("song %s: %w", id, err)
+		}
+	}
+	update(&s)
+	return put(b, id, s)
+}
+
+// Summaries returns the latest lookup of every looked up song in a deck, in
+// collect order.
+func (c *Catalog) Summaries(deck string) ([]models.GeneratedSong, error) {
+	var songs []models.GeneratedSong
+	err := c.db.View(func(tx *bolt.Tx) error {
+		members, err := deckMembers(tx, deck)
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			s, err := song(tx, SongID(m.URL))
+			if err != nil {
+				return err
+			}
+			if s != nil && s.Summary != nil {
+				songs = append(songs, *s.Summary)
+			}
+		}
+		return nil
+	})
+	return songs, err
+}
+
+// Song returns what the catalogue holds on a song and the decks it is in.
+// A song that was never collected or looked up is nil.
+func (c *Catalog) Song(id string) (*Song, []string, error) {
+	var s *Song
+	var decks []string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		var err error
+		if s, err = song(tx, id); err != nil {
+			return err
+		}
+		return tx.Bucket(decksBucket).ForEachBucket(func(deck []byte) error {
+			if tx.Bucket(decksBucket).Bucket(deck).Get([]byte(id)) != nil {
+				decks = append(decks, string(deck))
+			}
+			return nil
+		})
+	})
+	if err == nil && s == nil && len(decks) > 0 {
+		s = &Song{ID: id}
+	}
+	return s, decks, err
+}
+
+func song(tx *bolt.Tx, id string) (*Song, error) {
+	v := tx.Bucket(songsBucket).Get([]byte(id))
+	if v == nil {
+		return nil, nil
+	}
+	var s Song
+	if err := json.Unmarshal(v, &s); err != nil {
+		return nil, fmt.Errorf("song %s: %w", id, err)
+	}
+	return &s, nil
+}
+
+// AddPrint records a print run, setting its ID and time.
+func (c *Catalog) AddPrint(p *Print) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(printsBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		p.ID, p.At = id, now()
+		return put(b, string(binary.BigEndian.AppendUint64(nil, id)), p)
+	})
+}
+
+// Prints returns every print run, oldest first.
+func (c *Catalog) Prints() ([]Print, error) {
+	var prints []Print
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(printsBucket).ForEach(func(k, v []byte) error {
+			var p Print
+			if err := json.Unmarshal(v, &p); err != nil {
+				return fmt.Errorf("print %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			prints = append(prints, p)
+			return nil
+		})
+	})
+	return prints, err
+}
+
+func put(b *bolt.Bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
@@ -0,0 +1,215 @@
+package catalog
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"temporalize/internal/models"
+)
+
+func open(t *testing.T) (*Catalog, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalog.db")
+	c, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, path
+}
+
+func collected(ids ...string) []models.CollectedSong {
+	var songs []models.CollectedSong
+	for i, id := range ids {
+		songs = append(songs, models.CollectedSong{URL: "https://open.spotify.com/track/" + id, Genre: "pop", Year: 1980 + i})
+	}
+	return songs
+}
+
+func TestCollect(t *testing.T) {
+	c, _ := open(t)
+	if err := c.Collect("80s", collected("2WfaOiMkCvy7F5fcp2zZ8L", "3fH4KjXFYMmljxrcGrbPj9")); err != nil {
+		t.Fatal(err)
+	}
+	// Songs already in the deck keep their place.
+	if err := c.Collect("80s", collected("4yQw7FR9lcvL6RHtegbJBh", "2WfaOiMkCvy7F5fcp2zZ8L")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Collected("80s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(collected("2WfaOiMkCvy7F5fcp2zZ8L", "3fH4KjXFYMmljxrcGrbPj9"), collected("4yQw7FR9lcvL6RHtegbJBh")...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collected = %+v, want %+v", got, want)
+	}
+
+	if err := c.ClearDeck("80s"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Collected("80s"); err != nil || len(got) != 0 {
+		t.Errorf("Collected after ClearDeck = %v, %v", got, err)
+	}
+	if err := c.Collect("80s", []models.CollectedSong{{URL: "https://example.com/song"}}); err == nil {
+		t.Error("Collect accepted a song without a Spotify track")
+	}
+}
+
+func TestSummaryHistory(t *testing.T) {
+	c, _ := open(t)
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return day }
+	defer func() { now = time.Now }()
+
+	g := models.GeneratedSong{
+		Title:   "Take On Me",
+		Year:    1985,
+		Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+		Deezer:  "https://www.deezer.com/track/1",
+	}
+	g.SetStatus(models.PlatformSpotify, models.PlatformStatus{Status: models.StatusOK, Method: models.MethodSource, Score: 1})
+	g.SetStatus(models.PlatformDeezer, models.PlatformStatus{Status: models.StatusUnverified, Reason: "timeout"})
+	g.SetStatus(models.PlatformTidal, models.PlatformStatus{Status: models.StatusFailed, Reason: "no results"})
+	if err := c.PutSummary(g); err != nil {
+		t.Fatal(err)
+	}
+
+	// A day later only Deezer changed.
+	day = day.AddDate(0, 0, 1)
+	g.Platforms = nil
+	g.Deezer = "https://www.deezer.com/track/664107"
+	g.SetStatus(models.PlatformSpotify, models.PlatformStatus{Status: models.StatusOK, Method: models.MethodSource, Score: 1})
+	g.SetStatus(models.PlatformDeezer, models.PlatformStatus{Status: models.StatusFixed, Method: models.MethodISRC, Score: 1})
+	g.SetStatus(models.PlatformTidal, models.PlatformStatus{Status: models.StatusFailed, Reason: "no results"})
+	if err := c.PutSummary(g); err != nil {
+		t.Fatal(err)
+	}
+
+	s, decks, err := c.Song("2WfaOiMkCvy7F5fcp2zZ8L")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || !reflect.DeepEqual(*s.Summary, g) || !s.LookedUp.Equal(day) || len(decks) != 0 {
+		t.Fatalf("Song = %+v in %v, want the second summary in no deck", s, decks)
+	}
+	var history []string
+	for _, v := range s.History {
+		history = append(history, v.At.Format("01-02")+" "+v.Platform+" "+v.Status+" "+v.Link)
+	}
+	want := []string{
+		"03-01 deezer unverified https://www.deezer.com/track/1",
+		"03-01 spotify ok https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+		"03-01 tidal failed ",
+		"03-02 deezer fixed https://www.deezer.com/track/664107",
+	}
+	if !reflect.DeepEqual(history, want) {
+		t.Errorf("history =\n%s\nwant\n%s", strings.Join(history, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Summaries are listed in deck order, skipping songs not looked up.
+	if err := c.Collect("80s", collected("3fH4KjXFYMmljxrcGrbPj9", "2WfaOiMkCvy7F5fcp2zZ8L")); err != nil {
+		t.Fatal(err)
+	}
+	summaries, err := c.Summaries("80s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 || summaries[0].Title != "Take On Me" {
+		t.Errorf("Summaries = %+v", summaries)
+	}
+}
+
+func TestThumbnailAndPrints(t *testing.T) {
+	c, _ := open(t)
+	file := filepath.Join(t.TempDir(), "1985-Take On Me.png")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 64, 48))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if err := c.PutThumbnail("2WfaOiMkCvy7F5fcp2zZ8L", "https://i.scdn.co/image/a", file); err != nil {
+		t.Fatal(err)
+	}
+	s, _, err := c.Song("2WfaOiMkCvy7F5fcp2zZ8L")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th := s.Thumbnail; th == nil || th.Width != 64 || th.Height != 48 || th.Bytes == 0 || th.File != file {
+		t.Errorf("Thumbnail = %+v", s.Thumbnail)
+	}
+
+	for _, deck := range []string{"80s", "hip-hop-party"} {
+		if err := c.AddPrint(&Print{Deck: deck, Output: "out", Format: "png", Songs: []string{"2WfaOiMkCvy7F5fcp2zZ8L"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prints, err := c.Prints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prints) != 2 || prints[0].ID != 1 || prints[0].Deck != "80s" || prints[1].ID != 2 || prints[1].At.IsZero() {
+		t.Errorf("Prints = %+v", prints)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	c, path := open(t)
+	dir := t.TempDir()
+	songs := collected("2WfaOiMkCvy7F5fcp2zZ8L", "3fH4KjXFYMmljxrcGrbPj9")
+	summaries := []models.GeneratedSong{
+		{Title: "Take On Me", Year: 1985, Genre: "pop", Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L", Artists: []string{"a-ha"}},
+		// Looked up but not in the collect file, as after a manual edit.
+		{Title: "Money for Nothing", Year: 1985, Genre: "rock", Spotify: "https://open.spotify.com/track/4yQw7FR9lcvL6RHtegbJBh", Invalid: true},
+	}
+	if err := writeJSON(filepath.Join(dir, "collect.json"), songs); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, "lookup.json"), summaries); err != nil {
+		t.Fatal(err)
+	}
+
+	collectedN, lookedUp, err := c.Import("80s", filepath.Join(dir, "collect.json"), filepath.Join(dir, "lookup.json"))
+	if err != nil || collectedN != 2 || lookedUp != 2 {
+		t.Fatalf("Import = %d, %d, %v", collectedN, lookedUp, err)
+	}
+	// The catalogue outlives the command that wrote it.
+	c.Close()
+	if c, err = Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Export("80s", filepath.Join(dir, "collect-out.json"), filepath.Join(dir, "lookup-out.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotSongs []models.CollectedSong
+	if err := readJSON(filepath.Join(dir, "collect-out.json"), &gotSongs); err != nil {
+		t.Fatal(err)
+	}
+	wantSongs := append(songs, models.CollectedSong{URL: summaries[1].Spotify, Genre: "rock", Year: 1985})
+	if !reflect.DeepEqual(gotSongs, wantSongs) {
+		t.Errorf("exported collect = %+v, want %+v", gotSongs, wantSongs)
+	}
+	var gotSummaries []models.GeneratedSong
+	if err := readJSON(filepath.Join(dir, "lookup-out.json"), &gotSummaries); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotSummaries, summaries) {
+		t.Errorf("exported lookup = %+v, want %+v", gotSummaries, summaries)
+	}
+
+	// A second command cannot open the catalogue while it is in use.
+	if _, err := Open(path); err == nil || !strings.Contains(err.Error(), "open in another command") {
+		t.Errorf("second Open = %v", err)
+	}
+	c.Close()
+}
@@ -0,0 +1,98 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"temporalize/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Import adds the songs of a collect file to a deck, then stores the songs
+// of a lookup summary and adds those to the deck too. Either path may be
+// empty. It returns the number of songs read from each file.
+func (c *Catalog) Import(deck, collectFile, lookupFile string) (collected, lookedUp int, err error) {
+	var songs []models.CollectedSong
+	if collectFile != "" {
+		if err := readJSON(collectFile, &songs); err != nil {
+			return 0, 0, err
+		}
+	}
+	var summaries []models.GeneratedSong
+	if lookupFile != "" {
+		if err := readJSON(lookupFile, &summaries); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		if err := collect(tx, deck, songs); err != nil {
+			return err
+		}
+		for _, g := range summaries {
+			if err := putSummary(tx, g); err != nil {
+				return err
+			}
+			if err := collect(tx, deck, []models.CollectedSong{{URL: g.Spotify, Genre: g.Genre, Year: g.Year}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(songs), len(summaries), err
+}
+
+// Export writes the songs of a deck as a collect file and a lookup summary,
+// either of which may be skipped with an empty path. It returns the number
+// of songs written to each.
+func (c *Catalog) Export(deck, collectFile, lookupFile string) (collected, lookedUp int, err error) {
+	if collectFile != "" {
+		songs, err := c.Collected(deck)
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := writeJSON(collectFile, songs); err != nil {
+			return 0, 0, err
+		}
+		collected = len(songs)
+	}
+	if lookupFile != "" {
+		summaries, err := c.Summaries(deck)
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := writeJSON(lookupFile, summaries); err != nil {
+			return 0, 0, err
+		}
+		lookedUp = len(summaries)
+	}
+	return collected, lookedUp, nil
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON[T any](path string, v []T) error {
+	if v == nil {
+		v = []T{}
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
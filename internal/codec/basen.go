@@ -0,0 +1,95 @@
+package codec
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Alphabets
+const (
+	base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base36Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+)
+
+func decodeBaseN(s string, alphabet string) (*big.Int, error) {
+	val := big.NewInt(0)
+	base := big.NewInt(int64(len(alphabet)))
+
+	for _, c := range s {
+		idx := strings.IndexRune(alphabet, c)
+		if idx == -1 {
+			return nil, fmt.Errorf("invalid char %q in %q", c, s)
+		}
+		val.Mul(val, base)
+		val.Add(val, big.NewInt(int64(idx)))
+	}
+	return val, nil
+}
+
+func encodeBaseN(val *big.Int, alphabet string) string {
+	if val.Sign() == 0 {
+		return string(alphabet[0])
+	}
+
+	var res []byte
+	base := big.NewInt(int64(len(alphabet)))
+	zero := big.NewInt(0)
+	v := new(big.Int).Set(val)
+	mod := new(big.Int)
+
+	for v.Cmp(zero) > 0 {
+		v.DivMod(v, base, mod)
+		res = append(res, alphabet[mod.Int64()])
+	}
+
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return string(res)
+}
+
+func padString(s string, length int, padChar byte) string {
+	if len(s) >= length {
+		return s
+	}
+	return strings.Repeat(string(padChar), length-len(s)) + s
+}
+
+// putFixed decodes a BaseN ID and appends it to buf as a big-endian integer
+// left-padded to size bytes. An empty ID is written as zeros.
+func putFixed(buf []byte, name, id, alphabet string, size int) ([]byte, error) {
+	padded := make([]byte, size)
+	if id != "" {
+		val, err := decodeBaseN(id, alphabet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s id: %w", name, err)
+		}
+		b := val.Bytes()
+		if len(b) > size {
+			return nil, fmt.Errorf("%s id too long", name)
+		}
+		copy(padded[size-len(b):], b)
+	}
+	return append(buf, padded...), nil
+}
+
+// readFixed reverses putFixed, returning "" for an all-zero field.
+func readFixed(b []byte, alphabet string, width int) string {
+	val := new(big.Int).SetBytes(b)
+	if val.Sign() == 0 {
+		return ""
+	}
+	return padString(encodeBaseN(val, alphabet), width, alphabet[0])
+}
+
+// canonicalFixed returns id as Decode would report it: left-padded to width,
+// with any excess leading zero characters removed and all-zero IDs empty.
+func canonicalFixed(id, alphabet string, width int) string {
+	trimmed := strings.TrimLeft(id, alphabet[:1])
+	if trimmed == "" {
+		return ""
+	}
+	return padString(trimmed, width, alphabet[0])
+}
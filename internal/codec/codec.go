@@ -0,0 +1,154 @@
+// Package codec implements the binary payload stored in the QR code on the
+// back of each card.
+package codec
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Payload format versions. Version 1 is the original fixed-width layout and
+// has no header; every later version starts with a header byte.
+const (
+	Version1 = 1
+	Version2 = 2
+
+	CurrentVersion = Version2
+)
+
+// Field sizes in bytes and the decoded ID widths in characters.
+const (
+	amazonSize  = 7
+	spotifySize = 17
+	youtubeSize = 9
+
+	amazonIDLen  = 10
+	spotifyIDLen = 22
+	youtubeIDLen = 11
+
+	explicitBit = 1 << 7
+)
+
+var (
+	ErrShortData          = errors.New("short data")
+	ErrUnsupportedVersion = errors.New("unsupported payload version")
+)
+
+// CardPayload holds everything encoded into a card's QR code.
+// Amazon IDs are Base36 ASINs, Apple, Deezer and Tidal IDs are decimal,
+// Spotify IDs are Base62 and YouTube IDs use the URL-safe Base64 alphabet.
+// Empty fields are absent.
+type CardPayload struct {
+	// Version is the format to encode with; zero means CurrentVersion.
+	// Decode sets it to the version found in the data.
+	Version int
+
+	Explicit    bool
+	AmazonAlbum string
+	AmazonTrack string
+	AppleAlbum  string
+	AppleTrack  string
+	Spotify     string
+	YouTube     string
+	Deezer      string
+	Tidal       string
+}
+
+// Encode packs the payload into its binary form.
+func (p CardPayload) Encode() ([]byte, error) {
+	switch p.version() {
+	case Version1:
+		return p.encodeV1()
+	case Version2:
+		return p.encodeV2()
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, p.Version)
+	}
+}
+
+// Decode replaces the payload with the contents of data, detecting the
+// format version from its first byte. Fixed-width IDs are left-padded to
+// their canonical length.
+func (p *CardPayload) Decode(data []byte) error {
+	if len(data) == 0 {
+		return ErrShortData
+	}
+
+	var d CardPayload
+	var err error
+	switch v := headerVersion(data[0]); v {
+	case 0:
+		err = d.decodeV1(data)
+	case Version2:
+		err = d.decodeV2(data)
+	default:
+		err = fmt.Errorf("%w: %d", ErrUnsupportedVersion, v)
+	}
+	if err != nil {
+		return err
+	}
+
+	*p = d
+	return nil
+}
+
+// Canonical returns the payload as Decode would report it after a round
+// trip: fixed-width IDs are padded, Apple IDs lose leading zeros, zero-valued
+// IDs become empty and the version is made explicit.
+func (p CardPayload) Canonical() CardPayload {
+	c := p
+	c.Version = p.version()
+	c.AmazonAlbum = canonicalFixed(p.AmazonAlbum, base36Chars, amazonIDLen)
+	c.AmazonTrack = canonicalFixed(p.AmazonTrack, base36Chars, amazonIDLen)
+	c.AppleAlbum = canonicalDecimal(p.AppleAlbum)
+	c.AppleTrack = canonicalDecimal(p.AppleTrack)
+	c.Spotify = canonicalFixed(p.Spotify, base62Chars, spotifyIDLen)
+	c.YouTube = canonicalFixed(p.YouTube, base64Chars, youtubeIDLen)
+	c.Deezer = canonicalDecimal(p.Deezer)
+	c.Tidal = canonicalDecimal(p.Tidal)
+	return c
+}
+
+// DecodeBase64 decodes a payload written in base64, standard or URL safe,
+// with or without padding, as scanners and chat clients pass it on.
+func DecodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+func (p CardPayload) version() int {
+	if p.Version == 0 {
+		return CurrentVersion
+	}
+	return p.Version
+}
+
+// parseDecimalID parses a numeric platform ID. IDs are limited to 63 bits so
+// the Apple track delta cannot overflow.
+func parseDecimalID(name, id string) (uint64, error) {
+	if id == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(id, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s id: %w", name, err)
+	}
+	return v, nil
+}
+
+func canonicalDecimal(id string) string {
+	v, err := strconv.ParseUint(id, 10, 63)
+	if err != nil {
+		return id
+	}
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,424 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	maxAmazon  = strings.Repeat("Z", amazonIDLen)
+	maxSpotify = strings.Repeat("z", spotifyIDLen)
+	maxYouTube = strings.Repeat("_", youtubeIDLen)
+	maxApple   = "9223372036854775807"
+
+	fullPayload = CardPayload{
+		Explicit:    true,
+		AmazonAlbum: "B08XYZ1234",
+		AmazonTrack: "B08XYZ5678",
+		AppleAlbum:  "1440650428",
+		AppleTrack:  "1440650711",
+		Spotify:     "4cOdK2wGLETKBW3PvgPWqT",
+		YouTube:     "dQw4w9WgXcQ",
+	}
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		p    CardPayload
+	}{
+		{"empty", CardPayload{}},
+		{"explicit only", CardPayload{Explicit: true}},
+		{"full", fullPayload},
+		{"amazon album only", CardPayload{AmazonAlbum: "B08XYZ1234"}},
+		{"amazon track only", CardPayload{AmazonTrack: "B08XYZ5678"}},
+		{"amazon max", CardPayload{AmazonAlbum: maxAmazon, AmazonTrack: maxAmazon}},
+		{"amazon max explicit", CardPayload{Explicit: true, AmazonAlbum: maxAmazon}},
+		{"amazon short", CardPayload{AmazonAlbum: "ABC", AmazonTrack: "1"}},
+		{"apple album only", CardPayload{AppleAlbum: "1440650428"}},
+		{"apple track only", CardPayload{AppleTrack: "1440650711"}},
+		{"apple negative delta", CardPayload{AppleAlbum: "1500000000", AppleTrack: "1400000000"}},
+		{"apple same ids", CardPayload{AppleAlbum: "1440650428", AppleTrack: "1440650428"}},
+		{"apple max", CardPayload{AppleAlbum: maxApple, AppleTrack: maxApple}},
+		{"apple max album min track", CardPayload{AppleAlbum: maxApple, AppleTrack: "1"}},
+		{"apple min album max track", CardPayload{AppleAlbum: "1", AppleTrack: maxApple}},
+		{"apple leading zeros", CardPayload{AppleAlbum: "000123", AppleTrack: "0456"}},
+		{"spotify only", CardPayload{Spotify: "4cOdK2wGLETKBW3PvgPWqT"}},
+		{"spotify max", CardPayload{Spotify: maxSpotify}},
+		{"spotify leading zero", CardPayload{Spotify: "00dK2wGLETKBW3PvgPWqTa"}},
+		{"youtube only", CardPayload{YouTube: "dQw4w9WgXcQ"}},
+		{"youtube max", CardPayload{YouTube: maxYouTube}},
+		{"youtube leading zero char", CardPayload{YouTube: "AAw4w9WgXcQ"}},
+		{"all max", CardPayload{
+			Explicit:    true,
+			AmazonAlbum: maxAmazon,
+			AmazonTrack: maxAmazon,
+			AppleAlbum:  maxApple,
+			AppleTrack:  maxApple,
+			Spotify:     maxSpotify,
+			YouTube:     maxYouTube,
+		}},
+	}
+
+	for _, version := range []int{Version1, Version2} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("v%d/%s", version, tt.name), func(t *testing.T) {
+				p := tt.p
+				p.Version = version
+				data, err := p.Encode()
+				if err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+				var got CardPayload
+				if err := got.Decode(data); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				if want := p.Canonical(); got != want {
+					t.Errorf("Decode() = %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRoundTripNumericPlatforms(t *testing.T) {
+	tests := []struct {
+		name string
+		p    CardPayload
+	}{
+		{"deezer only", CardPayload{Deezer: "3135556"}},
+		{"tidal only", CardPayload{Tidal: "77646290"}},
+		{"deezer and tidal", CardPayload{Deezer: "3135556", Tidal: "77646290"}},
+		{"deezer and tidal max", CardPayload{Deezer: maxApple, Tidal: maxApple}},
+		{"deezer leading zeros", CardPayload{Deezer: "0003135556"}},
+		{"all platforms", CardPayload{
+			Explicit:    true,
+			AmazonAlbum: fullPayload.AmazonAlbum,
+			AmazonTrack: fullPayload.AmazonTrack,
+			AppleAlbum:  fullPayload.AppleAlbum,
+			AppleTrack:  fullPayload.AppleTrack,
+			Spotify:     fullPayload.Spotify,
+			YouTube:     fullPayload.YouTube,
+			Deezer:      "3135556",
+			Tidal:       "77646290",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.p.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			var got CardPayload
+			if err := got.Decode(data); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if want := tt.p.Canonical(); got != want {
+				t.Errorf("Decode() = %+v, want %+v", got, want)
+			}
+
+			v1 := tt.p
+			v1.Version = Version1
+			if _, err := v1.Encode(); err == nil {
+				t.Error("Encode() with Version1 want error")
+			}
+		})
+	}
+}
+
+func TestEncodeDefaultsToCurrentVersion(t *testing.T) {
+	p := fullPayload
+	p.Version = 0
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var got CardPayload
+	if err := got.Decode(data); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", got.Version, CurrentVersion)
+	}
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	// A v1 card as printed before the format was versioned: explicit, no
+	// Amazon, Apple 1440650428/1440650711, Spotify and YouTube.
+	data, err := hex.DecodeString("8000000000000000000000000000bca9faae05b604" +
+		"0000000000000000000000000000000000" + "0000000000000000" + "00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got CardPayload
+	if err := got.Decode(data); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := CardPayload{Version: Version1, Explicit: true, AppleAlbum: "1440650428", AppleTrack: "1440650711"}
+	if got != want {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeLayoutV1(t *testing.T) {
+	data, err := CardPayload{Version: Version1}.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	// Two zero varints for the Apple IDs.
+	want := 2*amazonSize + 2 + spotifySize + youtubeSize
+	if len(data) != want {
+		t.Errorf("len(Encode()) = %d, want %d", len(data), want)
+	}
+	if !bytes.Equal(data, make([]byte, want)) {
+		t.Errorf("Encode() = %x, want all zeros", data)
+	}
+
+	data, err = CardPayload{Version: Version1, Explicit: true}.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if data[0] != explicitBit {
+		t.Errorf("first byte = %#x, want %#x", data[0], explicitBit)
+	}
+}
+
+func TestEncodeLayoutV2(t *testing.T) {
+	tests := []struct {
+		name string
+		p    CardPayload
+		want int
+	}{
+		{"empty", CardPayload{}, 2},
+		{"spotify only", CardPayload{Spotify: fullPayload.Spotify}, 2 + spotifySize},
+		{"youtube only", CardPayload{YouTube: fullPayload.YouTube}, 2 + youtubeSize},
+		{"amazon same track", CardPayload{AmazonAlbum: "B08XYZ1234", AmazonTrack: "B08XYZ1234"}, 2 + amazonSize},
+		{"amazon", CardPayload{AmazonAlbum: "B08XYZ1234", AmazonTrack: "B08XYZ5678"}, 2 + 2*amazonSize},
+		{"full", fullPayload, 2 + spotifySize + 5 + 2 + 2*amazonSize + youtubeSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.p.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if len(data) != tt.want {
+				t.Errorf("len(Encode()) = %d, want %d", len(data), tt.want)
+			}
+			if v := headerVersion(data[0]); v != Version2 {
+				t.Errorf("header version = %d, want %d", v, Version2)
+			}
+			if explicit := data[0]&explicitBit != 0; explicit != tt.p.Explicit {
+				t.Errorf("explicit bit = %v, want %v", explicit, tt.p.Explicit)
+			}
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		p    CardPayload
+	}{
+		{"amazon album too long", CardPayload{AmazonAlbum: maxAmazon + "Z"}},
+		{"amazon album invalid char", CardPayload{AmazonAlbum: "b08xyz1234"}},
+		{"amazon track too long", CardPayload{AmazonTrack: maxAmazon + "Z"}},
+		{"amazon track invalid char", CardPayload{AmazonTrack: "B08-YZ5678"}},
+		{"apple album not a number", CardPayload{AppleAlbum: "abc"}},
+		{"apple album overflow", CardPayload{AppleAlbum: "9223372036854775808"}},
+		{"apple track negative", CardPayload{AppleTrack: "-1"}},
+		{"apple track overflow", CardPayload{AppleTrack: "18446744073709551615"}},
+		{"spotify too long", CardPayload{Spotify: maxSpotify + "z"}},
+		{"spotify invalid char", CardPayload{Spotify: "4cOdK2wGLETKBW3PvgPWq_"}},
+		{"youtube too long", CardPayload{YouTube: maxYouTube + "__"}},
+		{"youtube invalid char", CardPayload{YouTube: "dQw4w9WgXc="}},
+		{"deezer not a number", CardPayload{Deezer: "abc"}},
+		{"tidal overflow", CardPayload{Tidal: "9223372036854775808"}},
+	}
+
+	for _, version := range []int{Version1, Version2} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("v%d/%s", version, tt.name), func(t *testing.T) {
+				p := tt.p
+				p.Version = version
+				if data, err := p.Encode(); err == nil {
+					t.Errorf("Encode() = %x, want error", data)
+				}
+			})
+		}
+	}
+
+	if _, err := (CardPayload{Version: 7}).Encode(); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Encode() error = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestDecodeErrorsV1(t *testing.T) {
+	p := fullPayload
+	p.Version = Version1
+	full, err := p.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	appleEnd := 2*amazonSize + 10
+
+	tests := []struct {
+		name  string
+		data  []byte
+		short bool
+	}{
+		{"nil", nil, true},
+		{"amazon album truncated", full[:amazonSize-1], true},
+		{"amazon track truncated", full[:2*amazonSize-1], true},
+		{"missing apple album", full[:2*amazonSize], false},
+		{"missing apple track", full[:2*amazonSize+5], false},
+		{"spotify truncated", full[:appleEnd+spotifySize-1], true},
+		{"youtube truncated", full[:len(full)-1], true},
+		{"negative apple track", append(make([]byte, 2*amazonSize), 0x00, 0x01), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p CardPayload
+			err := p.Decode(tt.data)
+			if err == nil {
+				t.Fatalf("Decode() = %+v, want error", p)
+			}
+			if tt.short && !errors.Is(err, ErrShortData) {
+				t.Errorf("Decode() error = %v, want ErrShortData", err)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorsV2(t *testing.T) {
+	full, err := fullPayload.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		short bool
+	}{
+		{"nil", nil, true},
+		{"missing presence", full[:1], true},
+		{"spotify truncated", full[:2+spotifySize-1], true},
+		{"apple truncated", full[:2+spotifySize+2], false},
+		{"amazon truncated", full[:2+spotifySize+7+amazonSize], true},
+		{"youtube truncated", full[:len(full)-1], true},
+		{"deezer truncated", []byte{0x20, 0x10, 0x80}, false},
+		{"tidal missing", []byte{0x20, 0x20}, false},
+		{"unknown version", []byte{0x30, 0x00}, false},
+		{"unknown flag", []byte{0x22, 0x00}, false},
+		{"unknown field", []byte{0x20, 0x40}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p CardPayload
+			err := p.Decode(tt.data)
+			if err == nil {
+				t.Fatalf("Decode() = %+v, want error", p)
+			}
+			if tt.short && !errors.Is(err, ErrShortData) {
+				t.Errorf("Decode() error = %v, want ErrShortData", err)
+			}
+		})
+	}
+}
+
+func TestDecodeLeavesPayloadOnError(t *testing.T) {
+	p := fullPayload
+	if err := p.Decode([]byte{0x20, 0x01, 3}); err == nil {
+		t.Fatal("Decode() want error")
+	}
+	if p != fullPayload {
+		t.Errorf("payload modified on error: %+v", p)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(uint8(Version1), false, "", "", "", "", "", "", "", "")
+	f.Add(uint8(Version2), false, "", "", "", "", "", "", "", "")
+	f.Add(uint8(Version2), true, maxAmazon, maxAmazon, maxApple, maxApple, maxSpotify, maxYouTube, maxApple, maxApple)
+	f.Add(uint8(Version2), fullPayload.Explicit, fullPayload.AmazonAlbum, fullPayload.AmazonTrack,
+		fullPayload.AppleAlbum, fullPayload.AppleTrack, fullPayload.Spotify, fullPayload.YouTube, "3135556", "77646290")
+	f.Add(uint8(Version1), false, "0", "00", "0", "0", "0", "A", "0", "")
+
+	f.Fuzz(func(t *testing.T, version uint8, explicit bool, amzAlb, amzTrk, appAlb, appTrk, spot, yt, deezer, tidal string) {
+		p := CardPayload{
+			Version:     int(version%CurrentVersion) + 1,
+			Explicit:    explicit,
+			AmazonAlbum: amzAlb,
+			AmazonTrack: amzTrk,
+			AppleAlbum:  appAlb,
+			AppleTrack:  appTrk,
+			Spotify:     spot,
+			YouTube:     yt,
+			Deezer:      deezer,
+			Tidal:       tidal,
+		}
+		data, err := p.Encode()
+		if err != nil {
+			return
+		}
+		var got CardPayload
+		if err := got.Decode(data); err != nil {
+			t.Fatalf("Decode(Encode(%+v)) error = %v", p, err)
+		}
+		if want := p.Canonical(); got != want {
+			t.Fatalf("Decode(Encode(%+v)) = %+v, want %+v", p, got, want)
+		}
+	})
+}
+
+func FuzzDecode(f *testing.F) {
+	full, err := fullPayload.Encode()
+	if err != nil {
+		f.Fatalf("Encode() error = %v", err)
+	}
+	f.Add(full)
+	f.Add([]byte{})
+	f.Add(make([]byte, 2*amazonSize+2+spotifySize+youtubeSize))
+	f.Add(bytes.Repeat([]byte{0xff}, 64))
+	f.Add([]byte{0xa0, 0x0f})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p CardPayload
+		if err := p.Decode(data); err != nil {
+			return
+		}
+		again, err := p.Encode()
+		if err != nil {
+			t.Fatalf("Encode(%+v) error = %v", p, err)
+		}
+		var q CardPayload
+		if err := q.Decode(again); err != nil {
+			t.Fatalf("Decode(Encode(%+v)) error = %v", p, err)
+		}
+		if q != p {
+			t.Fatalf("Decode(Encode(%+v)) = %+v", p, q)
+		}
+	})
+}
+
+func TestDecodeBase64(t *testing.T) {
+	want := []byte{0xfb, 0xff, 0x01}
+	for _, s := range []string{"+/8B", "-_8B", " +/8B\n", "+/8B=="} {
+		got, err := DecodeBase64(s)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("DecodeBase64(%q) = %x, %v, want %x", s, got, err, want)
+		}
+	}
+	if _, err := DecodeBase64("not base64!"); err == nil {
+		t.Error("DecodeBase64 accepted garbage")
+	}
+}
@@ -0,0 +1,152 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// encodeV1 packs the payload in the original, unversioned layout.
+// Format:
+// [AmazonAlbum+Explicit (7 bytes)]
+// [AmazonTrack (7 bytes)]
+// [AppleAlbum (Uvarint)]
+// [AppleTrack (Varint Delta)]
+// [Spotify (17 bytes)]
+// [YouTube (9 bytes)]
+//
+// A 10 character ASIN needs at most 52 bits, so the top four bits of the
+// first byte only ever hold the explicit flag. Later versions rely on this to
+// tell their header apart.
+func (p CardPayload) encodeV1() ([]byte, error) {
+	if c := p.Canonical(); c.Deezer != "" || c.Tidal != "" {
+		return nil, fmt.Errorf("format v%d cannot store deezer or tidal ids", Version1)
+	}
+
+	var buf []byte
+	var err error
+
+	// Amazon Album + Explicit (7 bytes)
+	if buf, err = putFixed(buf, "amazon album", p.AmazonAlbum, base36Chars, amazonSize); err != nil {
+		return nil, err
+	}
+	if buf[0]&^headerFlagsMask != 0 {
+		return nil, fmt.Errorf("amazon album id too long")
+	}
+	// Set Explicit Bit (Bit 7 of byte 0)
+	if p.Explicit {
+		buf[0] |= explicitBit
+	}
+
+	// Amazon Track (7 bytes)
+	if buf, err = putFixed(buf, "amazon track", p.AmazonTrack, base36Chars, amazonSize); err != nil {
+		return nil, err
+	}
+
+	// Apple Album (Uvarint) + Apple Track (Varint Delta)
+	if buf, err = putApple(buf, p.AppleAlbum, p.AppleTrack); err != nil {
+		return nil, err
+	}
+
+	// Spotify (17 bytes)
+	if buf, err = putFixed(buf, "spotify", p.Spotify, base62Chars, spotifySize); err != nil {
+		return nil, err
+	}
+
+	// YouTube (9 bytes)
+	if buf, err = putFixed(buf, "youtube", p.YouTube, base64Chars, youtubeSize); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func (d *CardPayload) decodeV1(data []byte) error {
+	if len(data) < amazonSize { // Min length for AmzAlb
+		return ErrShortData
+	}
+
+	d.Version = Version1
+	idx := 0
+
+	// Amazon Album + Explicit (7 bytes)
+	amzAlbBytes := make([]byte, amazonSize)
+	copy(amzAlbBytes, data[idx:idx+amazonSize])
+	idx += amazonSize
+
+	d.Explicit = amzAlbBytes[0]&explicitBit != 0
+	// Clear Explicit bit for value decoding
+	amzAlbBytes[0] &^= explicitBit
+	d.AmazonAlbum = readFixed(amzAlbBytes, base36Chars, amazonIDLen)
+
+	// Amazon Track (7 bytes)
+	if idx+amazonSize > len(data) {
+		return fmt.Errorf("%w: amazon track", ErrShortData)
+	}
+	d.AmazonTrack = readFixed(data[idx:idx+amazonSize], base36Chars, amazonIDLen)
+	idx += amazonSize
+
+	// Apple Album (Uvarint) + Apple Track (Varint Delta)
+	n, err := d.readApple(data[idx:])
+	if err != nil {
+		return err
+	}
+	idx += n
+
+	// Spotify (17 bytes)
+	if idx+spotifySize > len(data) {
+		return fmt.Errorf("%w: spotify", ErrShortData)
+	}
+	d.Spotify = readFixed(data[idx:idx+spotifySize], base62Chars, spotifyIDLen)
+	idx += spotifySize
+
+	// YouTube (9 bytes)
+	if idx+youtubeSize > len(data) {
+		return fmt.Errorf("%w: youtube", ErrShortData)
+	}
+	d.YouTube = readFixed(data[idx:idx+youtubeSize], base64Chars, youtubeIDLen)
+
+	return nil
+}
+
+// putApple appends the album ID as a Uvarint followed by the track ID as a
+// Varint delta from the album. If Album is 0 (missing), Delta is just Track.
+func putApple(buf []byte, album, track string) ([]byte, error) {
+	appAlbVal, err := parseDecimalID("apple album", album)
+	if err != nil {
+		return nil, err
+	}
+	appTrkVal, err := parseDecimalID("apple track", track)
+	if err != nil {
+		return nil, err
+	}
+	buf = binary.AppendUvarint(buf, appAlbVal)
+	return binary.AppendVarint(buf, int64(appTrkVal)-int64(appAlbVal)), nil
+}
+
+// readApple reverses putApple and returns the number of bytes read.
+func (d *CardPayload) readApple(data []byte) (int, error) {
+	appAlbVal, n := binary.Uvarint(data)
+	if n <= 0 || appAlbVal > math.MaxInt64 {
+		return 0, fmt.Errorf("bad varint apple album")
+	}
+	idx := n
+	if appAlbVal > 0 {
+		d.AppleAlbum = strconv.FormatUint(appAlbVal, 10)
+	}
+
+	delta, n := binary.Varint(data[idx:])
+	if n <= 0 {
+		return 0, fmt.Errorf("bad varint apple track")
+	}
+	idx += n
+	appTrkVal := int64(appAlbVal) + delta
+	if appTrkVal < 0 {
+		return 0, fmt.Errorf("bad apple track delta %d", delta)
+	}
+	if appTrkVal > 0 {
+		d.AppleTrack = strconv.FormatInt(appTrkVal, 10)
+	}
+	return idx, nil
+}
@@ -0,0 +1,221 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Header byte layout for versioned payloads.
+const (
+	headerVersionShift = 4
+	headerVersionMask  = 0x7 << headerVersionShift
+	headerFlagsMask    = 0x0f
+
+	// flagAmazonSameTrack marks an Amazon track ASIN equal to the album ASIN,
+	// in which case the track is not stored.
+	flagAmazonSameTrack = 1 << 0
+)
+
+// Presence bitmap bits, in the order the fields are written.
+// New platforms must take the next free bit and append their field last.
+const (
+	hasSpotify = 1 << iota
+	hasApple
+	hasAmazon
+	hasYouTube
+	hasDeezer
+	hasTidal
+
+	knownFields = hasSpotify | hasApple | hasAmazon | hasYouTube | hasDeezer | hasTidal
+)
+
+func headerVersion(b byte) int {
+	return int(b&headerVersionMask) >> headerVersionShift
+}
+
+// encodeV2 packs the payload with a header and presence bitmap so that
+// absent platforms cost nothing.
+// Format:
+// [Header (1 byte): Explicit (bit 7), Version (bits 6-4), Flags (bits 3-0)]
+// [Presence (Uvarint)]
+// [Spotify (17 bytes)]
+// [AppleAlbum (Uvarint), AppleTrack (Varint Delta)]
+// [AmazonAlbum (7 bytes), AmazonTrack (7 bytes, omitted if same as album)]
+// [YouTube (9 bytes)]
+// [Deezer (Uvarint)]
+// [Tidal (Uvarint)]
+func (p CardPayload) encodeV2() ([]byte, error) {
+	c := p.Canonical()
+
+	header := byte(Version2 << headerVersionShift)
+	if p.Explicit {
+		header |= explicitBit
+	}
+
+	var present uint64
+	if c.Spotify != "" {
+		present |= hasSpotify
+	}
+	if c.AppleAlbum != "" || c.AppleTrack != "" {
+		present |= hasApple
+	}
+	if c.AmazonAlbum != "" || c.AmazonTrack != "" {
+		present |= hasAmazon
+		if c.AmazonAlbum == c.AmazonTrack {
+			header |= flagAmazonSameTrack
+		}
+	}
+	if c.YouTube != "" {
+		present |= hasYouTube
+	}
+	if c.Deezer != "" {
+		present |= hasDeezer
+	}
+	if c.Tidal != "" {
+		present |= hasTidal
+	}
+
+	buf := []byte{header}
+	buf = binary.AppendUvarint(buf, present)
+
+	var err error
+	if present&hasSpotify != 0 {
+		if buf, err = putFixed(buf, "spotify", p.Spotify, base62Chars, spotifySize); err != nil {
+			return nil, err
+		}
+	}
+	if present&hasApple != 0 {
+		if buf, err = putApple(buf, p.AppleAlbum, p.AppleTrack); err != nil {
+			return nil, err
+		}
+	}
+	if present&hasAmazon != 0 {
+		if buf, err = putFixed(buf, "amazon album", p.AmazonAlbum, base36Chars, amazonSize); err != nil {
+			return nil, err
+		}
+		if header&flagAmazonSameTrack == 0 {
+			if buf, err = putFixed(buf, "amazon track", p.AmazonTrack, base36Chars, amazonSize); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if present&hasYouTube != 0 {
+		if buf, err = putFixed(buf, "youtube", p.YouTube, base64Chars, youtubeSize); err != nil {
+			return nil, err
+		}
+	}
+	if present&hasDeezer != 0 {
+		if buf, err = putDecimal(buf, "deezer", p.Deezer); err != nil {
+			return nil, err
+		}
+	}
+	if present&hasTidal != 0 {
+		if buf, err = putDecimal(buf, "tidal", p.Tidal); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+func (d *CardPayload) decodeV2(data []byte) error {
+	header := data[0]
+	d.Version = Version2
+	d.Explicit = header&explicitBit != 0
+	if flags := header & headerFlagsMask; flags&^flagAmazonSameTrack != 0 {
+		return fmt.Errorf("unknown header flags %#x", flags)
+	}
+
+	present, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return fmt.Errorf("%w: presence", ErrShortData)
+	}
+	if present&^knownFields != 0 {
+		return fmt.Errorf("unknown fields in presence bitmap %#x", present)
+	}
+	idx := 1 + n
+
+	if present&hasSpotify != 0 {
+		if idx+spotifySize > len(data) {
+			return fmt.Errorf("%w: spotify", ErrShortData)
+		}
+		d.Spotify = readFixed(data[idx:idx+spotifySize], base62Chars, spotifyIDLen)
+		idx += spotifySize
+	}
+
+	if present&hasApple != 0 {
+		n, err := d.readApple(data[idx:])
+		if err != nil {
+			return err
+		}
+		idx += n
+	}
+
+	if present&hasAmazon != 0 {
+		if idx+amazonSize > len(data) {
+			return fmt.Errorf("%w: amazon album", ErrShortData)
+		}
+		d.AmazonAlbum = readFixed(data[idx:idx+amazonSize], base36Chars, amazonIDLen)
+		idx += amazonSize
+
+		if header&flagAmazonSameTrack != 0 {
+			d.AmazonTrack = d.AmazonAlbum
+		} else {
+			if idx+amazonSize > len(data) {
+				return fmt.Errorf("%w: amazon track", ErrShortData)
+			}
+			d.AmazonTrack = readFixed(data[idx:idx+amazonSize], base36Chars, amazonIDLen)
+			idx += amazonSize
+		}
+	}
+
+	if present&hasYouTube != 0 {
+		if idx+youtubeSize > len(data) {
+			return fmt.Errorf("%w: youtube", ErrShortData)
+		}
+		d.YouTube = readFixed(data[idx:idx+youtubeSize], base64Chars, youtubeIDLen)
+		idx += youtubeSize
+	}
+
+	if present&hasDeezer != 0 {
+		id, n, err := readDecimal(data[idx:], "deezer")
+		if err != nil {
+			return err
+		}
+		d.Deezer = id
+		idx += n
+	}
+
+	if present&hasTidal != 0 {
+		id, _, err := readDecimal(data[idx:], "tidal")
+		if err != nil {
+			return err
+		}
+		d.Tidal = id
+	}
+
+	return nil
+}
+
+// putDecimal appends a numeric ID as a Uvarint.
+func putDecimal(buf []byte, name, id string) ([]byte, error) {
+	v, err := parseDecimalID(name, id)
+	if err != nil {
+		return nil, err
+	}
+	return binary.AppendUvarint(buf, v), nil
+}
+
+// readDecimal reverses putDecimal and returns the number of bytes read.
+func readDecimal(data []byte, name string) (string, int, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 || v > math.MaxInt64 {
+		return "", 0, fmt.Errorf("bad varint %s", name)
+	}
+	if v == 0 {
+		return "", n, nil
+	}
+	return strconv.FormatUint(v, 10), n, nil
+}
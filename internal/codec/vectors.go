@@ -0,0 +1,181 @@
+package codec
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Vector is a golden test vector: payload bytes and the canonical form of
+// the payload they were encoded from, which decoding must yield. The web
+// scanner's decoder is checked against the same vectors, so the two
+// implementations cannot drift apart.
+type Vector struct {
+	Name string `json:"name"`
+	// Payload is the encoded payload in hex.
+	Payload string `json:"payload"`
+	// Decoded is nil for payloads every decoder must reject.
+	Decoded *VectorFields `json:"decoded"`
+}
+
+// VectorFields is a decoded payload, with the field names the web decoder
+// uses.
+type VectorFields struct {
+	Version     int    `json:"version"`
+	Explicit    bool   `json:"explicit"`
+	AmazonAlbum string `json:"amazonAlbum"`
+	AmazonTrack string `json:"amazonTrack"`
+	AppleAlbum  string `json:"appleAlbum"`
+	AppleTrack  string `json:"appleTrack"`
+	Spotify     string `json:"spotify"`
+	YouTube     string `json:"youtube"`
+	Deezer      string `json:"deezer"`
+	Tidal       string `json:"tidal"`
+}
+
+var (
+	vectorMaxApple   = "9223372036854775807"
+	vectorMaxAmazon  = strings.Repeat("Z", amazonIDLen)
+	vectorMaxSpotify = strings.Repeat("z", spotifyIDLen)
+	vectorMaxYouTube = strings.Repeat("_", youtubeIDLen)
+
+	vectorFull = CardPayload{
+		Explicit:    true,
+		AmazonAlbum: "B08XYZ1234",
+		AmazonTrack: "B08XYZ5678",
+		AppleAlbum:  "1440650428",
+		AppleTrack:  "1440650711",
+		Spotify:     "4cOdK2wGLETKBW3PvgPWqT",
+		YouTube:     "dQw4w9WgXcQ",
+		Deezer:      "3135556",
+		Tidal:       "1234567",
+	}
+)
+
+// vectorPayloads are encoded in every version that can hold them.
+var vectorPayloads = []struct {
+	name string
+	p    CardPayload
+}{
+	{"empty", CardPayload{}},
+	{"explicit only", CardPayload{Explicit: true}},
+	{"full", vectorFull},
+	{"spotify only", CardPayload{Spotify: "4cOdK2wGLETKBW3PvgPWqT"}},
+	{"spotify leading zero", CardPayload{Spotify: "00dK2wGLETKBW3PvgPWqTa"}},
+	{"apple negative delta", CardPayload{AppleAlbum: "1500000000", AppleTrack: "1400000000"}},
+	{"apple album only", CardPayload{AppleAlbum: "1440650428"}},
+	{"apple track only", CardPayload{AppleTrack: "1440650711"}},
+	{"apple max album min track", CardPayload{AppleAlbum: vectorMaxApple, AppleTrack: "1"}},
+	{"amazon same track", CardPayload{AmazonAlbum: "B08XYZ1234", AmazonTrack: "B08XYZ1234"}},
+	{"amazon short", CardPayload{AmazonAlbum: "ABC", AmazonTrack: "1"}},
+	{"youtube leading zero char", CardPayload{YouTube: "AAw4w9WgXcQ"}},
+	{"deezer and tidal max", CardPayload{Deezer: vectorMaxApple, Tidal: vectorMaxApple}},
+	{"all max", CardPayload{
+		Explicit:    true,
+		AmazonAlbum: vectorMaxAmazon,
+		AmazonTrack: vectorMaxAmazon,
+		AppleAlbum:  vectorMaxApple,
+		AppleTrack:  vectorMaxApple,
+		Spotify:     vectorMaxSpotify,
+		YouTube:     vectorMaxYouTube,
+		Deezer:      vectorMaxApple,
+		Tidal:       vectorMaxApple,
+	}},
+}
+
+// Vectors returns the golden vectors: every payload above in each version
+// that holds it, then payloads that must be rejected.
+func Vectors() ([]Vector, error) {
+	var vectors []Vector
+	for _, version := range []int{Version1, Version2} {
+		for _, v := range vectorPayloads {
+			p := v.p
+			p.Version = version
+			// Version 1 has no Deezer or Tidal fields.
+			if version == Version1 && (p.Deezer != "" || p.Tidal != "") {
+				p.Deezer, p.Tidal = "", ""
+				if p == (CardPayload{Version: Version1}) {
+					continue
+				}
+			}
+			name := fmt.Sprintf("v%d %s", version, v.name)
+			data, err := p.Encode()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			// The expected fields come from the source payload, not the
+			// decoder, so a decoding bug cannot end up in the vectors.
+			want := p.Canonical()
+			var got CardPayload
+			if err := got.Decode(data); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if got != want {
+				return nil, fmt.Errorf("%s: decoded to %+v, want %+v", name, got, want)
+			}
+			vectors = append(vectors, Vector{Name: name, Payload: hex.EncodeToString(data), Decoded: vectorFields(want)})
+		}
+	}
+
+	full, err := vectorFull.Encode()
+	if err != nil {
+		return nil, err
+	}
+	v1 := vectorFull
+	v1.Version, v1.Deezer, v1.Tidal = Version1, "", ""
+	fullV1, err := v1.Encode()
+	if err != nil {
+		return nil, err
+	}
+	for _, bad := range []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"v1 amazon truncated", fullV1[:amazonSize-1]},
+		{"v1 negative apple track", append(make([]byte, 2*amazonSize), 0x00, 0x01)},
+		{"v1 youtube truncated", fullV1[:len(fullV1)-1]},
+		{"v2 missing presence", full[:1]},
+		{"v2 spotify truncated", full[:2+spotifySize-1]},
+		{"v2 tidal missing", []byte{0x20, 0x20}},
+		{"unknown version", []byte{0x30, 0x00}},
+		{"v2 unknown flag", []byte{0x22, 0x00}},
+		{"v2 unknown field", []byte{0x20, 0x40}},
+	} {
+		var p CardPayload
+		if err := p.Decode(bad.data); err == nil {
+			return nil, fmt.Errorf("%s: decoded to %+v, want an error", bad.name, p)
+		}
+		vectors = append(vectors, Vector{Name: "invalid " + bad.name, Payload: hex.EncodeToString(bad.data)})
+	}
+	return vectors, nil
+}
+
+// vectorFields returns the fields of p under the web decoder's names.
+func vectorFields(p CardPayload) *VectorFields {
+	return &VectorFields{
+		Version:     p.Version,
+		Explicit:    p.Explicit,
+		AmazonAlbum: p.AmazonAlbum,
+		AmazonTrack: p.AmazonTrack,
+		AppleAlbum:  p.AppleAlbum,
+		AppleTrack:  p.AppleTrack,
+		Spotify:     p.Spotify,
+		YouTube:     p.YouTube,
+		Deezer:      p.Deezer,
+		Tidal:       p.Tidal,
+	}
+}
+
+// WriteVectors writes the golden vectors as indented JSON.
+func WriteVectors(w io.Writer) error {
+	vectors, err := Vectors()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vectors)
+}
@@ -0,0 +1,81 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/vectors.json")
+
+var vectorsPath = filepath.Join("testdata", "vectors.json")
+
+func TestVectorsUpToDate(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVectors(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(vectorsPath, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("%s does not match the codec. If the format change is intended, run\n"+
+			"\tgo test ./internal/codec -run TestVectors -update\n"+
+			"and update web/decode.ts until task test:web passes.", vectorsPath)
+	}
+}
+
+func TestVectors(t *testing.T) {
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		payload, err := hex.DecodeString(v.Payload)
+		if err != nil {
+			t.Fatalf("%s: %v", v.Name, err)
+		}
+		var p CardPayload
+		err = p.Decode(payload)
+		if v.Decoded == nil {
+			if err == nil {
+				t.Errorf("%s: Decode() = %+v, want error", v.Name, p)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Decode() error = %v", v.Name, err)
+			continue
+		}
+		want := CardPayload{
+			Version:     v.Decoded.Version,
+			Explicit:    v.Decoded.Explicit,
+			AmazonAlbum: v.Decoded.AmazonAlbum,
+			AmazonTrack: v.Decoded.AmazonTrack,
+			AppleAlbum:  v.Decoded.AppleAlbum,
+			AppleTrack:  v.Decoded.AppleTrack,
+			Spotify:     v.Decoded.Spotify,
+			YouTube:     v.Decoded.YouTube,
+			Deezer:      v.Decoded.Deezer,
+			Tidal:       v.Decoded.Tidal,
+		}
+		if p != want {
+			t.Errorf("%s: Decode() = %+v, want %+v", v.Name, p, want)
+		}
+	}
+}
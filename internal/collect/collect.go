@@ -0,0 +1,265 @@
+// Package collect finds the most popular Spotify tracks of each year and
+// genre group, the first stage of building a deck.
+package collect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/config"
+	"temporalize/internal/endpoints"
+	"temporalize/internal/models"
+	"temporalize/internal/spotifyclient"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Options configure a collect run.
+type Options struct {
+	OutputFile        string
+	StartYear         int
+	EndYear           int
+	MinPopularity     int
+	MaxTracksPerGenre int
+	// Genres maps each genre to the Spotify genres searched for it.
+	Genres      map[string][]string
+	Upstream    endpoints.Endpoints
+	Credentials spotifyclient.Credentials
+	// Catalog, when set, is the catalogue the songs are collected into as
+	// Deck instead of OutputFile.
+	Catalog string
+	Deck    string
+}
+
+// NewOptions returns the options cfg sets.
+func NewOptions(cfg config.Config) (Options, error) {
+	genres, err := cfg.Taxonomy()
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		OutputFile:        cfg.Paths.Collect,
+		StartYear:         cfg.Years.Start,
+		EndYear:           cfg.Years.End,
+		MinPopularity:     cfg.Collect.MinPopularity,
+		MaxTracksPerGenre: cfg.Collect.MaxTracksPerGenre,
+		Genres:            genres.Search(),
+		Upstream:          endpoints.Default,
+		Credentials:       spotifyclient.FromEnv(),
+		Catalog:           cfg.Paths.Catalog,
+		Deck:              cfg.Deck,
+	}, nil
+}
+
+// Run writes the collected songs to opts.OutputFile, or the catalogue, as
+// they are found.
+func Run(opts Options) error {
+	if err := opts.Credentials.Check(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := spotifyclient.New(ctx, opts.Credentials, opts.Upstream, spotifyclient.Options{})
+	startYear, endYear := opts.StartYear, opts.EndYear
+
+	out, err := newOutput(opts)
+	if err != nil {
+		return err
+	}
+
+	uniqueLinks := make(map[string]bool)
+
+	// Sort keys for deterministic iteration
+	var genreKeys []string
+	for k := range opts.Genres {
+		genreKeys = append(genreKeys, k)
+	}
+	sort.Strings(genreKeys)
+
+	for year := startYear; year <= endYear; year++ {
+		fmt.Printf("Collecting songs for %d...\n", year)
+
+		// Collect candidates for this year
+		// Map URL -> Genre (first one wins)
+		yearSongs := make(map[string]string)
+
+		for _, group := range genreKeys {
+			subgenres := opts.Genres[group]
+			links, err := getTopSongs(ctx, client, opts, year, subgenres)
+			if err != nil {
+				log.Printf("Failed to get songs for %d (group %s): %v", year, group, err)
+				continue
+			}
+			for _, link := range links {
+				if _, exists := yearSongs[link]; !exists {
+					yearSongs[link] = group
+				}
+			}
+		}
+
+		// Add unique new links to the master list
+		// We want to add them in a deterministic order if possible, or just iterate.
+		// Since map iteration is random, let's sort the URLs we found this year to be stable.
+		var yearURLs []string
+		for link := range yearSongs {
+			yearURLs = append(yearURLs, link)
+		}
+		sort.Strings(yearURLs)
+
+		var added []models.CollectedSong
+		for _, link := range yearURLs {
+			if !uniqueLinks[link] {
+				uniqueLinks[link] = true
+				added = append(added, models.CollectedSong{URL: link, Genre: yearSongs[link], Year: year})
+			}
+		}
+		// Write each year immediately
+		if err := out.add(added); err != nil {
+			out.close()
+			return err
+		}
+		fmt.Printf("  -> Added %d unique songs for %d\n", len(added), year)
+	}
+
+	return out.close()
+}
+
+// output receives each year's songs as they are collected.
+type output interface {
+	add(songs []models.CollectedSong) error
+	close() error
+}
+
+// newOutput starts the collect file, or empties the deck in the catalogue.
+func newOutput(opts Options) (output, error) {
+	if opts.Catalog != "" {
+		cat, err := catalog.Open(opts.Catalog)
+		if err != nil {
+			return nil, err
+		}
+		if err := cat.ClearDeck(opts.Deck); err != nil {
+			cat.Close()
+			return nil, err
+		}
+		fmt.Printf("Collecting deck %s into %s\n", opts.Deck, opts.Catalog)
+		return catalogOutput{cat: cat, deck: opts.Deck}, nil
+	}
+
+	// To truly stream, we open the file at the start, write "[", and then
+	// append items, so a crash keeps every year written.
+	if err := os.MkdirAll(filepath.Dir(opts.OutputFile), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(opts.OutputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	if _, err := f.WriteString("[\n"); err != nil {
+		f.Close()
+		return nil, err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("  ", "  ")
+	return &fileOutput{f: f, encoder: encoder, firstItem: true}, nil
+}
+
+type fileOutput struct {
+	f         *os.File
+	encoder   *json.Encoder
+	firstItem bool
+}
+
+func (o *fileOutput) add(songs []models.CollectedSong) error {
+	for _, song := range songs {
+		if !o.firstItem {
+			if _, err := o.f.WriteString(",\n"); err != nil {
+				return err
+			}
+		}
+		if err := o.encoder.Encode(song); err != nil {
+			return err
+		}
+		o.firstItem = false
+	}
+	return nil
+}
+
+// close writes the closing bracket.
+func (o *fileOutput) close() error {
+	if _, err := o.f.WriteString("]"); err != nil {
+		o.f.Close()
+		return err
+	}
+	return o.f.Close()
+}
+
+type catalogOutput struct {
+	cat  *catalog.Catalog
+	deck string
+}
+
+func (o catalogOutput) add(songs []models.CollectedSong) error {
+	return o.cat.Collect(o.deck, songs)
+}
+
+func (o catalogOutput) close() error {
+	return o.cat.Close()
+}
+
+func getTopSongs(ctx context.Context, client *spotify.Client, opts Options, year int, genres []string) ([]string, error) {
+	trackIDs := make(map[spotify.ID]spotify.FullTrack)
+
+	for _, genre := range genres {
+		query := fmt.Sprintf("genre:%q year:%d", genre, year)
+		// Fetch up to 200 tracks per genre (4 pages of 50)
+		for offset := 0; offset < 500; offset += 50 {
+			results, err := client.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(50), spotify.Offset(offset))
+			if err != nil {
+				log.Printf("Error searching Spotify for genre %q year %d (offset %d): %v", genre, year, offset, err)
+				continue
+			}
+
+			for _, item := range results.Tracks.Tracks {
+				if int(item.Popularity) >= opts.MinPopularity {
+					trackIDs[item.ID] = item
+				}
+			}
+			if len(results.Tracks.Tracks) < 50 { // Less than a full page, no more results
+				break
+			}
+		}
+	}
+
+	// Convert map to slice for sorting
+	var tracks []spotify.FullTrack
+	for _, track := range trackIDs {
+		tracks = append(tracks, track)
+	}
+
+	// Sort by popularity descending
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].Popularity > tracks[j].Popularity
+	})
+
+	// Take top N unique tracks
+	var topSpotifyLinks []string
+	for i, track := range tracks {
+		if i >= opts.MaxTracksPerGenre {
+			break
+		}
+		// Use ExternalURLs["spotify"] if available, otherwise construct URI
+		link := track.ExternalURLs["spotify"]
+		if link == "" {
+			link = "https://open.spotify.com/track/" + string(track.ID)
+		}
+		topSpotifyLinks = append(topSpotifyLinks, link)
+	}
+
+	return topSpotifyLinks, nil
+}
@@ -0,0 +1,277 @@
+// Package config loads the project configuration: the genres with their
+// search terms and card themes, collection and matching thresholds, card
+// dimensions and file paths. Every value defaults to what the commands used
+// before it existed, so a deck's config file only lists what it changes.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+
+	"temporalize/internal/genre"
+	"temporalize/internal/match"
+	"temporalize/internal/overrides"
+	"temporalize/internal/policy"
+	"temporalize/internal/sheets"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is loaded when it exists and no other file is given.
+const DefaultPath = "temporalize.yaml"
+
+// CardFormats are the file formats cards can be drawn in: png is raster
+// at the card's DPI, svg and pdf are vector with the fonts embedded.
+var CardFormats = []string{"png", "svg", "pdf"}
+
+// QRLevels are the QR error correction levels, recovering about 7%, 15%,
+// 25% and 30% of a damaged code.
+var QRLevels = []string{"L", "M", "Q", "H"}
+
+type Config struct {
+	// Deck names the deck's songs and print runs in the catalogue.
+	Deck     string                 `yaml:"deck"`
+	Years    Years                  `yaml:"years"`
+	Collect  Collect                `yaml:"collect"`
+	Lookup   Lookup                 `yaml:"lookup"`
+	Generate Generate               `yaml:"generate"`
+	Card     Card                   `yaml:"card"`
+	Genres   map[string]genre.Genre `yaml:"genres"`
+	Paths    Paths                  `yaml:"paths"`
+}
+
+// Years is the inclusive range of release years in the deck.
+type Years struct {
+	Start int `yaml:"start"`
+	End   int `yaml:"end"`
+}
+
+type Collect struct {
+	// MinPopularity is the lowest Spotify popularity (0-100) collected.
+	MinPopularity int `yaml:"min_popularity"`
+	// MaxTracksPerGenre caps the songs kept per genre and year.
+	MaxTracksPerGenre int `yaml:"max_tracks_per_genre"`
+}
+
+type Lookup struct {
+	Concurrency    int     `yaml:"concurrency"`
+	MatchThreshold float64 `yaml:"match_threshold"`
+	YearTolerance  int     `yaml:"year_tolerance"`
+}
+
+type Generate struct {
+	// Require is the platform policy, see policy.Parse.
+	Require         string `yaml:"require"`
+	AllowUnverified bool   `yaml:"allow_unverified"`
+	// Sheets names the paper to impose cards on, see sheets.Papers. Empty
+	// writes no sheets.
+	Sheets       string `yaml:"sheets"`
+	Duplex       string `yaml:"duplex"`
+	Registration bool   `yaml:"registration"`
+	// Verify scans every card back after drawing it.
+	Verify bool `yaml:"verify"`
+}
+
+// Card sets the printed card geometry in inches and the fonts and icons
+// drawn on it.
+type Card struct {
+	// Format is the card file format, see CardFormats.
+	Format string  `yaml:"format"`
+	DPI    float64 `yaml:"dpi"`
+	Bleed  float64 `yaml:"bleed"`
+	Margin float64 `yaml:"margin"`
+	// Standard and USMini are the trimmed card sizes. Text scales with the
+	// width relative to USMini.
+	Standard Size `yaml:"standard"`
+	USMini   Size `yaml:"usmini"`
+
+	TitleFont string `yaml:"title_font"`
+	TextFont  string `yaml:"text_font"`
+
+	ArtistIcon   string `yaml:"artist_icon"`
+	SongIcon     string `yaml:"song_icon"`
+	ExplicitIcon string `yaml:"explicit_icon"`
+
+	// BackColor fills the back around the QR code.
+	BackColor genre.Color `yaml:"back_color"`
+	QR        QR          `yaml:"qr"`
+	// DefaultTheme is used for genres without a theme of their own.
+	DefaultTheme genre.Theme `yaml:"default_theme"`
+}
+
+// QR sets how the code on the back is encoded and drawn.
+type QR struct {
+	// Level is the error correction level, see QRLevels.
+	Level string `yaml:"level"`
+	// QuietZone is the light border around the code in modules. Scanners
+	// expect at least 4.
+	QuietZone int `yaml:"quiet_zone"`
+	// MinModule is the smallest module printed, in inches. A code that
+	// needs smaller modules to fit the safe area is an error.
+	MinModule float64     `yaml:"min_module"`
+	Dark      genre.Color `yaml:"dark"`
+	Light     genre.Color `yaml:"light"`
+}
+
+type Size struct {
+	Width  float64 `yaml:"width"`
+	Height float64 `yaml:"height"`
+}
+
+type Paths struct {
+	Collect    string `yaml:"collect"`
+	Lookup     string `yaml:"lookup"`
+	Overrides  string `yaml:"overrides"`
+	Cache      string `yaml:"cache"`
+	Thumbnails string `yaml:"thumbnails"`
+	Output     string `yaml:"output"`
+	// Catalog is the catalogue database commands use instead of the
+	// collect and lookup files; empty keeps the files.
+	Catalog string `yaml:"catalog"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Deck:     "default",
+		Years:    Years{Start: 1970, End: 2025},
+		Collect:  Collect{MinPopularity: 40, MaxTracksPerGenre: 10},
+		Lookup:   Lookup{Concurrency: 4, MatchThreshold: match.DefaultThreshold, YearTolerance: 1},
+		Generate: Generate{Require: policy.Default, Duplex: string(sheets.LongEdge)},
+		Card: Card{
+			Format:       "png",
+			DPI:          300,
+			Bleed:        0.125,
+			Margin:       0.125,
+			Standard:     Size{Width: 2.5, Height: 3.5},
+			USMini:       Size{Width: 1.625, Height: 2.5},
+			TitleFont:    "assets/fonts/Lobster-Regular.ttf",
+			TextFont:     "assets/fonts/Arial.ttf",
+			ArtistIcon:   "assets/icons/artistIcon.png",
+			SongIcon:     "assets/icons/songIcon.png",
+			ExplicitIcon: "assets/icons/explicit.png",
+			BackColor:    genre.Color{R: 0, G: 0, B: 0, A: 255},
+			QR: QR{
+				Level:     "Q",
+				QuietZone: 4,
+				MinModule: 0.01,
+				Dark:      genre.Color{R: 0, G: 0, B: 0, A: 255},
+				Light:     genre.Color{R: 255, G: 255, B: 255, A: 255},
+			},
+			DefaultTheme: genre.Theme{Light: genre.Color{R: 211, G: 211, B: 211, A: 255}, Dark: genre.Color{R: 64, G: 64, B: 64, A: 255}},
+		},
+		Genres: genre.Defaults(),
+		Paths: Paths{
+			Collect:    "collect.json",
+			Lookup:     "lookup.json",
+			Overrides:  overrides.DefaultPath,
+			Cache:      ".cache/lookup",
+			Thumbnails: "thumbnails",
+			Output:     "assets/generated",
+		},
+	}
+}
+
+// Load reads a YAML config file over the defaults and validates the
+// result. An empty path loads DefaultPath if it exists. A file that sets
+// genres replaces the default genres rather than adding to them.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		if _, err := os.Stat(DefaultPath); err != nil {
+			return cfg, nil
+		}
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Genres = nil
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && err != io.EOF {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Genres == nil {
+		cfg.Genres = Default().Genres
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports every invalid value.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Deck != "", "deck: must be set")
+	check(c.Years.Start > 0 && c.Years.End > 0, "years: start and end must be set")
+	check(c.Years.Start <= c.Years.End, "years: start %d is after end %d", c.Years.Start, c.Years.End)
+
+	check(c.Collect.MinPopularity >= 0 && c.Collect.MinPopularity <= 100, "collect.min_popularity: %d is not between 0 and 100", c.Collect.MinPopularity)
+	check(c.Collect.MaxTracksPerGenre > 0, "collect.max_tracks_per_genre: must be positive")
+
+	check(c.Lookup.Concurrency > 0, "lookup.concurrency: must be positive")
+	check(c.Lookup.MatchThreshold >= 0 && c.Lookup.MatchThreshold <= 1, "lookup.match_threshold: %v is not between 0 and 1", c.Lookup.MatchThreshold)
+	check(c.Lookup.YearTolerance >= 0, "lookup.year_tolerance: must not be negative")
+
+	if _, err := policy.Parse(c.Generate.Require); err != nil {
+		errs = append(errs, fmt.Errorf("generate.require: %w", err))
+	}
+	if c.Generate.Sheets != "" {
+		if _, err := sheets.ParsePaper(c.Generate.Sheets); err != nil {
+			errs = append(errs, fmt.Errorf("generate.sheets: %w", err))
+		}
+	}
+	if _, err := sheets.ParseDuplex(c.Generate.Duplex); err != nil {
+		errs = append(errs, fmt.Errorf("generate.duplex: %w", err))
+	}
+
+	check(slices.Contains(CardFormats, c.Card.Format), "card.format: unknown format %q, want one of %v", c.Card.Format, CardFormats)
+	check(c.Card.DPI > 0, "card.dpi: must be positive")
+	check(c.Card.Bleed >= 0, "card.bleed: must not be negative")
+	check(c.Card.Margin >= 0, "card.margin: must not be negative")
+	for name, s := range map[string]Size{"standard": c.Card.Standard, "usmini": c.Card.USMini} {
+		check(s.Width > 2*c.Card.Margin && s.Height > 2*c.Card.Margin, "card.%s: %vx%v leaves no room inside a %v margin", name, s.Width, s.Height, c.Card.Margin)
+	}
+	check(slices.Contains(QRLevels, c.Card.QR.Level), "card.qr.level: unknown level %q, want one of %v", c.Card.QR.Level, QRLevels)
+	check(c.Card.QR.QuietZone >= 0, "card.qr.quiet_zone: must not be negative")
+	check(c.Card.QR.MinModule > 0, "card.qr.min_module: must be positive")
+	for name, path := range map[string]string{
+		"title_font": c.Card.TitleFont, "text_font": c.Card.TextFont,
+		"artist_icon": c.Card.ArtistIcon, "song_icon": c.Card.SongIcon, "explicit_icon": c.Card.ExplicitIcon,
+	} {
+		check(path != "", "card.%s: must be set", name)
+	}
+
+	if _, err := c.Taxonomy(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for name, path := range map[string]string{
+		"collect": c.Paths.Collect, "lookup": c.Paths.Lookup, "thumbnails": c.Paths.Thumbnails, "output": c.Paths.Output,
+	} {
+		check(path != "", "paths.%s: must be set", name)
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// Taxonomy returns the genre taxonomy. Load has checked the genres already.
+func (c Config) Taxonomy() (*genre.Taxonomy, error) {
+	return genre.New(c.Genres, c.Card.DefaultTheme)
+}
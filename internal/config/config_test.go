@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"temporalize/internal/genre"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "deck.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load(\"\") = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadOverlaysDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+years: {start: 1980, end: 1989}
+card:
+  dpi: 600
+  back_color: "#102030"
+  qr: {level: H}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Default()
+	want.Years = Years{Start: 1980, End: 1989}
+	want.Card.DPI = 600
+	want.Card.BackColor = genre.Color{R: 0x10, G: 0x20, B: 0x30, A: 255}
+	want.Card.QR.Level = "H"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadReplacesGenres(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+genres:
+  hip-hop:
+    search: [hip hop, rap]
+    match: [hip hop, rap]
+    theme: {light: "#ffa07a", dark: "#8b0000", icon: assets/icons/hiphopIcon.jpg}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	genres, err := cfg.Taxonomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := genres.Names(); !reflect.DeepEqual(got, []string{"hip-hop"}) {
+		t.Errorf("genres = %v, want only hip-hop", got)
+	}
+	if theme := cfg.Genres["hip-hop"].Theme; theme == nil || theme.Dark != (genre.Color{R: 0x8b, G: 0, B: 0, A: 255}) {
+		t.Errorf("hip-hop theme = %+v", theme)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"unknown field", "card:\n  dpii: 300\n", []string{"field dpii not found"}},
+		{"bad colour", "card:\n  back_color: black\n", []string{`line 2: invalid colour "black"`}},
+		{"values", `
+deck: ""
+years: {start: 1990, end: 1980}
+collect: {min_popularity: 101}
+lookup: {match_threshold: 2}
+generate: {require: spotify+napster, sheets: a3, duplex: sideways}
+card: {margin: 2, format: jpeg, qr: {level: X, quiet_zone: -1, min_module: 0}}
+`, []string{
+			"years: start 1990 is after end 1980",
+			"collect.min_popularity: 101 is not between 0 and 100",
+			"lookup.match_threshold: 2 is not between 0 and 1",
+			`generate.require: invalid policy "spotify+napster"`,
+			`generate.sheets: unknown paper "a3"`,
+			`generate.duplex: unknown duplex "sideways"`,
+			`card.format: unknown format "jpeg"`,
+			`card.qr.level: unknown level "X"`,
+			"card.qr.quiet_zone: must not be negative",
+			"card.qr.min_module: must be positive",
+			"card.standard: 2.5x3.5 leaves no room inside a 2 margin",
+			"card.usmini: 1.625x2.5 leaves no room inside a 2 margin",
+		}},
+		{"no searchable genre", "genres:\n  funk:\n    match: [funk]\n", []string{"genres: no genre has search terms"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.content)
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load succeeded")
+			}
+			if !strings.HasPrefix(err.Error(), path+": ") {
+				t.Errorf("error %q does not name the file", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecks(t *testing.T) {
+	paths, err := filepath.Glob("../../decks/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no decks found")
+	}
+	decks := make(map[string]string)
+	for _, path := range paths {
+		cfg, err := Load(path)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		// Decks sharing a catalogue must not share a name.
+		if other, ok := decks[cfg.Deck]; ok {
+			t.Errorf("%s and %s are both deck %q", other, path, cfg.Deck)
+		}
+		decks[cfg.Deck] = path
+	}
+}
@@ -0,0 +1,57 @@
+// Package endpoints lists the base URLs of the upstream services the
+// commands talk to, so that tests and mirrors can replace them.
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Endpoints are service roots without a trailing slash, except SpotifyAPI
+// which zmb3/spotify expects to end in one.
+type Endpoints struct {
+	SpotifyAPI   string `json:"spotify_api"`
+	SpotifyToken string `json:"spotify_token"`
+	Odesli       string `json:"odesli"`
+	ITunes       string `json:"itunes"`
+	AppleMusic   string `json:"apple_music"`
+	Amazon       string `json:"amazon"`
+	AmazonMusic  string `json:"amazon_music"`
+	YouTube      string `json:"youtube"`
+	Deezer       string `json:"deezer"`
+	Tidal        string `json:"tidal"`
+	MusicBrainz  string `json:"musicbrainz"`
+}
+
+// Default are the public services.
+var Default = Endpoints{
+	SpotifyAPI:   "https://api.spotify.com/v1/",
+	SpotifyToken: "https://accounts.spotify.com/api/token",
+	Odesli:       "https://api.song.link",
+	ITunes:       "https://itunes.apple.com",
+	AppleMusic:   "https://music.apple.com",
+	Amazon:       "https://www.amazon.com",
+	AmazonMusic:  "https://music.amazon.com",
+	YouTube:      "https://www.youtube.com",
+	Deezer:       "https://api.deezer.com",
+	Tidal:        "https://tidal.com",
+	MusicBrainz:  "https://musicbrainz.org",
+}
+
+// Load reads a JSON file overriding some of the default endpoints. An empty
+// path returns the defaults.
+func Load(path string) (Endpoints, error) {
+	e := Default
+	if path == "" {
+		return e, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Endpoints{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,320 @@
+// Package fakes serves recorded upstream responses from a single httptest
+// server, so collect, lookup and generate can run end to end without the
+// network. Fixtures live under fixtures/<service>/ and are keyed by the ID
+// or the slug of the search term a request carries; requests without a
+// fixture get the answer the real service gives for an unknown item.
+package fakes
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"temporalize/internal/endpoints"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// recordedImageHost is where recorded Spotify responses point thumbnails;
+// it is rewritten to the fake's /images/ route.
+const recordedImageHost = "https://i.scdn.co/image/"
+
+// Server is a running fake of every upstream service.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	misses   []string
+}
+
+// New starts a fake server. Close it when done.
+func New() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/spotify/api/token", s.spotifyToken)
+	mux.HandleFunc("/spotify/v1/tracks/", s.fixture("spotify/tracks", spotifyKey, ".json", missJSON(http.StatusNotFound, `{"error":{"status":404,"message":"Not found."}}`)))
+	mux.HandleFunc("/spotify/v1/artists/", s.fixture("spotify/artists", spotifyKey, ".json", missJSON(http.StatusNotFound, `{"error":{"status":404,"message":"Not found."}}`)))
+	mux.HandleFunc("/spotify/v1/search", s.spotifySearch)
+	mux.HandleFunc("/images/", s.image)
+	mux.HandleFunc("/odesli/v1-alpha.1/links", s.fixture("odesli", odesliKey, ".json", missJSON(http.StatusBadRequest, `{"statusCode":400,"code":"could_not_resolve_entity"}`)))
+	mux.HandleFunc("/itunes/lookup", s.fixture("itunes/lookup", queryKey("id"), ".json", missJSON(http.StatusOK, `{"resultCount":0,"results":[]}`)))
+	mux.HandleFunc("/itunes/search", s.fixture("itunes/search", queryKey("term"), ".json", missJSON(http.StatusOK, `{"resultCount":0,"results":[]}`)))
+	mux.HandleFunc("/apple-music/", s.fixture("apple/track", queryKey("i"), ".html", missHTML(http.StatusNotFound)))
+	mux.HandleFunc("/amazon/s", s.fixture("amazon/search", queryKey("k"), ".html", missHTML(http.StatusOK)))
+	mux.HandleFunc("/amazon-music/embed/", s.fixture("amazon/embed", pathKey, ".html", missHTML(http.StatusNotFound)))
+	mux.HandleFunc("/youtube/results", s.fixture("youtube/results", queryKey("search_query"), ".html", missHTML(http.StatusOK)))
+	mux.HandleFunc("/youtube/oembed", s.fixture("youtube/oembed", oembedKey, ".json", missJSON(http.StatusNotFound, "Not Found")))
+	mux.HandleFunc("/deezer/track/", s.fixture("deezer/track", pathKey, ".json", missJSON(http.StatusOK, `{"error":{"type":"DataException","message":"no data","code":800}}`)))
+	mux.HandleFunc("/deezer/search", s.fixture("deezer/search", queryKey("q"), ".json", missJSON(http.StatusOK, `{"data":[],"total":0}`)))
+	mux.HandleFunc("/tidal/search", s.fixture("tidal/search", queryKey("q"), ".html", missHTML(http.StatusOK)))
+	mux.HandleFunc("/tidal/browse/track/", s.fixture("tidal/track", pathKey, ".html", missHTML(http.StatusNotFound)))
+	mux.HandleFunc("/musicbrainz/ws/2/isrc/", s.fixture("musicbrainz/isrc", pathKey, ".json", missJSON(http.StatusNotFound, `{"error":"Not Found"}`)))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.record(r, true)
+		http.NotFound(w, r)
+	})
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Endpoints points every service at the fake.
+func (s *Server) Endpoints() endpoints.Endpoints {
+	return endpoints.Endpoints{
+		SpotifyAPI:   s.URL + "/spotify/v1/",
+		SpotifyToken: s.URL + "/spotify/api/token",
+		Odesli:       s.URL + "/odesli",
+		ITunes:       s.URL + "/itunes",
+		AppleMusic:   s.URL + "/apple-music",
+		Amazon:       s.URL + "/amazon",
+		AmazonMusic:  s.URL + "/amazon-music",
+		YouTube:      s.URL + "/youtube",
+		Deezer:       s.URL + "/deezer",
+		Tidal:        s.URL + "/tidal",
+		MusicBrainz:  s.URL + "/musicbrainz",
+	}
+}
+
+// Requests returns the path and query of every request served so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Misses returns the requests that had no fixture.
+func (s *Server) Misses() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.misses...)
+}
+
+func (s *Server) record(r *http.Request, miss bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	if miss {
+		s.misses = append(s.misses, r.URL.RequestURI())
+	}
+}
+
+// fixture serves dir/<key><ext>, or miss when the file does not exist.
+func (s *Server) fixture(dir string, key func(*http.Request) string, ext string, miss http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		data, err := fs.ReadFile(fixtures, path.Join("fixtures", dir, k+ext))
+		if k == "" || err != nil {
+			s.record(r, true)
+			miss(w, r)
+			return
+		}
+		s.record(r, false)
+		if ext == ".json" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write(s.rewrite(data))
+	}
+}
+
+// rewrite points recorded thumbnail URLs at the fake.
+func (s *Server) rewrite(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte(recordedImageHost), []byte(s.URL+"/images/"))
+}
+
+func missJSON(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+func missHTML(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprint(w, "<!DOCTYPE html><html><head><title>Not found</title></head><body></body></html>")
+	}
+}
+
+// pathKey keys a request by the slug of its last path segment.
+func pathKey(r *http.Request) string {
+	return Slug(path.Base(r.URL.Path))
+}
+
+// spotifyKey keys a request by the Spotify ID ending its path. IDs are case
+// sensitive, so they are used as is.
+func spotifyKey(r *http.Request) string {
+	return path.Base(r.URL.Path)
+}
+
+// queryKey keys a request by the slug of a query parameter.
+func queryKey(name string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return Slug(r.URL.Query().Get(name))
+	}
+}
+
+// odesliKey keys a links request by the Spotify track ID.
+func odesliKey(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Query().Get("url"), "spotify:track:")
+}
+
+// oembedKey keys an oEmbed request by the video ID of its url parameter.
+func oembedKey(r *http.Request) string {
+	u, err := url.Parse(r.URL.Query().Get("url"))
+	if err != nil {
+		return ""
+	}
+	return Slug(u.Query().Get("v"))
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug is the fixture file name for a search term or ID: lower case, runs
+// of other characters replaced by "-".
+func Slug(s string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func (s *Server) spotifyToken(w http.ResponseWriter, r *http.Request) {
+	s.record(r, false)
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"access_token":"fake-token","token_type":"Bearer","expires_in":3600}`)
+}
+
+var searchFilter = regexp.MustCompile(`(genre|year):("[^"]*"|\S+)`)
+
+// spotifySearch answers track searches from the recorded tracks, honouring
+// the genre: and year: filters collect uses. A track matches a genre
+// when one of its first artist's genres contains it.
+func (s *Server) spotifySearch(w http.ResponseWriter, r *http.Request) {
+	s.record(r, false)
+	q := r.URL.Query()
+	var genre, year string
+	for _, m := range searchFilter.FindAllStringSubmatch(q.Get("q"), -1) {
+		value := strings.Trim(m[2], `"`)
+		if m[1] == "genre" {
+			genre = strings.ToLower(value)
+		} else {
+			year = value
+		}
+	}
+
+	tracks, err := s.searchTracks(genre, year)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	total := len(tracks)
+	tracks = tracks[min(offset, total):min(offset+limit, total)]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"tracks": map[string]any{"items": tracks, "limit": limit, "offset": offset, "total": total},
+	})
+}
+
+func (s *Server) searchTracks(genre, year string) ([]json.RawMessage, error) {
+	entries, err := fs.ReadDir(fixtures, "fixtures/spotify/tracks")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	tracks := []json.RawMessage{}
+	for _, name := range names {
+		data, err := fs.ReadFile(fixtures, "fixtures/spotify/tracks/"+name)
+		if err != nil {
+			return nil, err
+		}
+		var track struct {
+			Album struct {
+				ReleaseDate string `json:"release_date"`
+			} `json:"album"`
+			Artists []struct {
+				ID string `json:"id"`
+			} `json:"artists"`
+		}
+		if err := json.Unmarshal(data, &track); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if year != "" && !strings.HasPrefix(track.Album.ReleaseDate, year) {
+			continue
+		}
+		if genre != "" && (len(track.Artists) == 0 || !s.artistHasGenre(track.Artists[0].ID, genre)) {
+			continue
+		}
+		tracks = append(tracks, s.rewrite(data))
+	}
+	return tracks, nil
+}
+
+func (s *Server) artistHasGenre(id, genre string) bool {
+	data, err := fs.ReadFile(fixtures, "fixtures/spotify/artists/"+id+".json")
+	if err != nil {
+		return false
+	}
+	var artist struct {
+		Genres []string `json:"genres"`
+	}
+	if json.Unmarshal(data, &artist) != nil {
+		return false
+	}
+	for _, g := range artist.Genres {
+		if strings.Contains(strings.ToLower(g), genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// image serves a small JPEG whose colour is derived from its name, standing
+// in for album art.
+func (s *Server) image(w http.ResponseWriter, r *http.Request) {
+	s.record(r, false)
+	h := fnv.New32a()
+	h.Write([]byte(r.URL.Path))
+	sum := h.Sum32()
+	c := color.RGBA{uint8(sum), uint8(sum >> 8), uint8(sum >> 16), 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	jpeg.Encode(w, img, nil)
+}
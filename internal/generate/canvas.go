@@ -0,0 +1,142 @@
+package generate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+
+	"temporalize/internal/config"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+// canvas is a card face being drawn. Coordinates are pixels at the card's
+// DPI from the top left corner, bleed included, whatever the format, so
+// the layout code is shared; vector formats only map them to inches.
+type canvas interface {
+	// Clear fills the whole face.
+	Clear(c color.Color)
+	FillRoundedRect(x, y, w, h, radius float64, c color.Color)
+	// DrawImage draws img scaled to w by h centred on cx, cy, clipped to
+	// that box with rounded corners when radius is positive.
+	DrawImage(img image.Image, cx, cy, w, h, radius float64)
+	SetFont(f *cardFont, size float64)
+	MeasureString(s string) float64
+	WordWrap(s string, width float64) []string
+	// DrawString draws s centred on x, y, upside down if rotated.
+	DrawString(s string, x, y float64, c color.Color, rotated bool)
+	// DrawQR draws q, quiet zone included, with its top left at x, y and
+	// modules module pixels wide.
+	DrawQR(q qrSymbol, x, y, module float64)
+	// Save writes the face to path.
+	Save(path string) error
+}
+
+// newCanvas returns an empty face of the given size in the format.
+func newCanvas(format string, width, height int, dpi float64) (canvas, error) {
+	switch format {
+	case "png":
+		return newRasterCanvas(width, height), nil
+	case "svg":
+		return newSVGCanvas(width, height, dpi), nil
+	case "pdf":
+		return newPDFCanvas(width, height, dpi), nil
+	}
+	return nil, fmt.Errorf("unknown card format %q, want one of %v", format, config.CardFormats)
+}
+
+// cardFont is a TrueType font with the data vector formats embed.
+type cardFont struct {
+	data []byte
+	ttf  *truetype.Font
+}
+
+func loadFont(path string) (*cardFont, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cardFont{data: data, ttf: ttf}, nil
+}
+
+// textMetrics measures text as the raster canvas draws it, so vector faces
+// wrap and centre lines identically.
+type textMetrics struct {
+	dc     *gg.Context
+	font   *cardFont
+	size   float64
+	height float64
+}
+
+func newTextMetrics() textMetrics {
+	return textMetrics{dc: gg.NewContext(1, 1)}
+}
+
+func (m *textMetrics) SetFont(f *cardFont, size float64) {
+	face := truetype.NewFace(f.ttf, &truetype.Options{Size: size})
+	m.dc.SetFontFace(face)
+	m.font, m.size = f, size
+	_, m.height = m.dc.MeasureString("")
+}
+
+func (m *textMetrics) MeasureString(s string) float64 {
+	w, _ := m.dc.MeasureString(s)
+	return w
+}
+
+func (m *textMetrics) WordWrap(s string, width float64) []string {
+	return m.dc.WordWrap(s, width)
+}
+
+// baseline returns where the baseline of s starts when it is centred on
+// x, y, as gg.Context.DrawStringAnchored places it.
+func (m *textMetrics) baseline(s string, x, y float64) (float64, float64) {
+	return x - m.MeasureString(s)/2, y + m.height/2
+}
+
+// qrSymbol is an encoded QR code, without the border qrcode.QRCode adds,
+// and how it is drawn.
+type qrSymbol struct {
+	// bitmap is true for dark modules.
+	bitmap [][]bool
+	// quiet is the light border around the code in modules.
+	quiet       int
+	dark, light color.Color
+	version     int
+}
+
+// size is the width of the symbol in modules, quiet zone included.
+func (q qrSymbol) size() int {
+	return len(q.bitmap) + 2*q.quiet
+}
+
+// qrRun is a horizontal run of dark modules.
+type qrRun struct {
+	row, col, length int
+}
+
+// runs returns the dark runs of q, counted from the top left of the quiet
+// zone, for the canvases to draw as rectangles.
+func (q qrSymbol) runs() []qrRun {
+	var runs []qrRun
+	for row, modules := range q.bitmap {
+		for col := 0; col < len(modules); {
+			if !modules[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(modules) && modules[col] {
+				col++
+			}
+			runs = append(runs, qrRun{row: row + q.quiet, col: start + q.quiet, length: col - start})
+		}
+	}
+	return runs
+}
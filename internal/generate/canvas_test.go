@@ -0,0 +1,132 @@
+package generate
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"temporalize/internal/config"
+	"temporalize/internal/models"
+)
+
+func testRenderer(t *testing.T) *renderer {
+	t.Helper()
+	card := config.Default().Card
+	card.TitleFont = filepath.Join("../..", card.TitleFont)
+	return &renderer{card: card, format: "png"}
+}
+
+func testSymbol(t *testing.T, r *renderer) qrSymbol {
+	t.Helper()
+	q, err := r.createQRCode(&models.Song{Spotify: "4iV5W9uYEdYUVa79Axb7Rh", Deezer: "3135556"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestQRRuns(t *testing.T) {
+	q := testSymbol(t, testRenderer(t))
+	q.quiet = 2
+	n := q.size()
+	if n != len(q.bitmap)+4 {
+		t.Fatalf("size = %d, want %d", n, len(q.bitmap)+4)
+	}
+
+	got := make([][]bool, n)
+	for i := range got {
+		got[i] = make([]bool, n)
+	}
+	runs := q.runs()
+	for i, r := range runs {
+		if i > 0 && runs[i-1].row == r.row && runs[i-1].col+runs[i-1].length >= r.col {
+			t.Errorf("runs %v and %v touch", runs[i-1], r)
+		}
+		for c := r.col; c < r.col+r.length; c++ {
+			got[r.row][c] = true
+		}
+	}
+	for y := range got {
+		for x := range got[y] {
+			want := y >= 2 && x >= 2 && y < n-2 && x < n-2 && q.bitmap[y-2][x-2]
+			if got[y][x] != want {
+				t.Fatalf("module %d,%d = %v, want %v", x, y, got[y][x], want)
+			}
+		}
+	}
+}
+
+func TestDrawBackCrispModules(t *testing.T) {
+	r := testRenderer(t)
+	// Colours the back and its text do not use.
+	r.card.QR.Dark.B = 0x80
+	r.card.QR.Light.B = 0xf0
+	q := testSymbol(t, r)
+	path := filepath.Join(t.TempDir(), "back.png")
+	if err := r.drawBack(q, r.card.USMini.Width, r.card.USMini.Height, path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every pixel of the symbol is a module colour, and the quiet zone is
+	// light.
+	dark, light := color.RGBAModel.Convert(q.dark), color.RGBAModel.Convert(q.light)
+	var bounds image.Rectangle
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			if c := color.RGBAModel.Convert(img.At(x, y)); c == light || c == dark {
+				bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if bounds.Dx() != bounds.Dy() || bounds.Dx()%q.size() != 0 {
+		t.Fatalf("symbol is %v, want a square of whole %d module rows", bounds, q.size())
+	}
+	module := bounds.Dx() / q.size()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			row, col := (y-bounds.Min.Y)/module-q.quiet, (x-bounds.Min.X)/module-q.quiet
+			want := light
+			if row >= 0 && col >= 0 && row < len(q.bitmap) && col < len(q.bitmap) && q.bitmap[row][col] {
+				want = dark
+			}
+			if got := color.RGBAModel.Convert(img.At(x, y)); got != want {
+				t.Fatalf("pixel %d,%d = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestDrawBackTooSmall(t *testing.T) {
+	r := testRenderer(t)
+	r.card.QR.MinModule = 0.05
+	err := r.drawBack(testSymbol(t, r), r.card.USMini.Width, r.card.USMini.Height, filepath.Join(t.TempDir(), "back.png"))
+	if err == nil || !strings.Contains(err.Error(), "more than the 1.38in safe area") {
+		t.Errorf("drawBack = %v, want a safe area error", err)
+	}
+}
+
+func TestSVGImageError(t *testing.T) {
+	c := newSVGCanvas(100, 100, 100)
+	// An empty image cannot be encoded as PNG.
+	c.DrawImage(image.NewRGBA(image.Rect(0, 0, 0, 0)), 50, 50, 20, 20, 0)
+	path := filepath.Join(t.TempDir(), "front.svg")
+	if err := c.Save(path); err == nil || !strings.Contains(err.Error(), "failed to encode image") {
+		t.Errorf("Save = %v, want the image error", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("Save wrote a card missing its image")
+	}
+}
@@ -0,0 +1,91 @@
+package generate
+
+import (
+	"fmt"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+)
+
+const debugCompression = true
+
+// songPayload builds the QR payload from the song's compact platform IDs.
+func songPayload(s *models.Song) (codec.CardPayload, error) {
+	p := codec.CardPayload{Explicit: s.Explicit}
+	for _, pl := range platform.All() {
+		id := s.Link(pl.Key())
+		if id == "" {
+			continue
+		}
+		if err := pl.Encode(id, &p); err != nil {
+			return codec.CardPayload{}, err
+		}
+	}
+	return p, nil
+}
+
+// compress generates the compressed byte slice for the QR code.
+func compress(p codec.CardPayload) ([]byte, error) {
+	buf, err := p.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	if debugCompression {
+		if err := verifyCompression(buf, p); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+func verifyCompression(buf []byte, p codec.CardPayload) error {
+	var decoded codec.CardPayload
+	if err := decoded.Decode(buf); err != nil {
+		return fmt.Errorf("sanity check failed: decompression error: %w", err)
+	}
+
+	want := p.Canonical()
+	if decoded.Version != want.Version {
+		return fmt.Errorf("sanity check failed: version mismatch: got %d, want %d", decoded.Version, want.Version)
+	}
+	if decoded.Explicit != want.Explicit {
+		return fmt.Errorf("sanity check failed: explicit mismatch")
+	}
+
+	check := func(name, expected, got string) error {
+		if got != expected {
+			return fmt.Errorf("sanity check failed: %s mismatch: got %q, want %q", name, got, expected)
+		}
+		return nil
+	}
+
+	if err := check("AmazonAlbum", want.AmazonAlbum, decoded.AmazonAlbum); err != nil {
+		return err
+	}
+	if err := check("AmazonTrack", want.AmazonTrack, decoded.AmazonTrack); err != nil {
+		return err
+	}
+	if err := check("AppleAlbum", want.AppleAlbum, decoded.AppleAlbum); err != nil {
+		return err
+	}
+	if err := check("AppleTrack", want.AppleTrack, decoded.AppleTrack); err != nil {
+		return err
+	}
+	if err := check("Spotify", want.Spotify, decoded.Spotify); err != nil {
+		return err
+	}
+	if err := check("YouTube", want.YouTube, decoded.YouTube); err != nil {
+		return err
+	}
+	if err := check("Deezer", want.Deezer, decoded.Deezer); err != nil {
+		return err
+	}
+	if err := check("Tidal", want.Tidal, decoded.Tidal); err != nil {
+		return err
+	}
+
+	return nil
+}
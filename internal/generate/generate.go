@@ -0,0 +1,254 @@
+// Package generate prints the card fronts and backs for looked up songs.
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/config"
+	"temporalize/internal/genre"
+	"temporalize/internal/models"
+	"temporalize/internal/overrides"
+	"temporalize/internal/platform"
+	"temporalize/internal/policy"
+	"temporalize/internal/sheets"
+)
+
+// Options configure a generate run.
+type Options struct {
+	InputFile     string
+	OutputDir     string
+	OverridesFile string
+	// Require is the platform policy, see policy.Parse.
+	Require         string
+	AllowUnverified bool
+	Card            config.Card
+	// Genres hold the card themes.
+	Genres       *genre.Taxonomy
+	ThumbnailDir string
+	// Sheets names the paper to impose the cards on as PDF, empty for
+	// none.
+	Sheets       string
+	Duplex       string
+	Registration bool
+	// Verify scans every back written and fails the cards whose code does
+	// not decode to their links.
+	Verify bool
+	// Catalog, when set, is the catalogue Deck is read from instead of
+	// InputFile, and each run is recorded in.
+	Catalog string
+	Deck    string
+}
+
+// NewOptions returns the options cfg sets.
+func NewOptions(cfg config.Config) (Options, error) {
+	genres, err := cfg.Taxonomy()
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		InputFile:       cfg.Paths.Lookup,
+		OutputDir:       cfg.Paths.Output,
+		OverridesFile:   cfg.Paths.Overrides,
+		Require:         cfg.Generate.Require,
+		AllowUnverified: cfg.Generate.AllowUnverified,
+		Card:            cfg.Card,
+		Genres:          genres,
+		ThumbnailDir:    cfg.Paths.Thumbnails,
+		Sheets:          cfg.Generate.Sheets,
+		Duplex:          cfg.Generate.Duplex,
+		Registration:    cfg.Generate.Registration,
+		Verify:          cfg.Generate.Verify,
+		Catalog:         cfg.Paths.Catalog,
+		Deck:            cfg.Deck,
+	}, nil
+}
+
+// Run prints a card for every song in the summary that meets the policy.
+func Run(opts Options) error {
+	cardPolicy, err := policy.Parse(opts.Require)
+	if err != nil {
+		return err
+	}
+	cardPolicy.AllowUnverified = opts.AllowUnverified
+	var sheetOpts *sheets.Options
+	if opts.Sheets != "" {
+		if opts.Card.Format != "png" {
+			return fmt.Errorf("sheets are imposed from png cards, not %s", opts.Card.Format)
+		}
+		paper, err := sheets.ParsePaper(opts.Sheets)
+		if err != nil {
+			return err
+		}
+		duplex, err := sheets.ParseDuplex(opts.Duplex)
+		if err != nil {
+			return err
+		}
+		sheetOpts = &sheets.Options{Paper: paper, Duplex: duplex, Registration: opts.Registration}
+	}
+
+	if opts.Verify && opts.Card.Format != "png" {
+		return fmt.Errorf("only png card backs can be scanned to verify them, not %s", opts.Card.Format)
+	}
+
+	genSongs, input, err := opts.songs()
+	if err != nil {
+		return fmt.Errorf("failed to read generated songs: %w", err)
+	}
+	fmt.Printf("Loaded %d songs from %s\n", len(genSongs), input)
+
+	r := &renderer{card: opts.Card, genres: opts.Genres, thumbnailDir: opts.ThumbnailDir, format: opts.Card.Format, verify: opts.Verify}
+	printed, err := run(r, genSongs, opts.OutputDir, opts.OverridesFile, cardPolicy)
+	if err != nil {
+		return err
+	}
+	if opts.Catalog != "" {
+		if err := recordPrint(opts, printed); err != nil {
+			return err
+		}
+	}
+	if sheetOpts != nil {
+		return r.writeSheets(printed, opts.OutputDir, *sheetOpts)
+	}
+	return nil
+}
+
+// run generates the assets of every card and returns the songs printed.
+func run(r *renderer, genSongs []models.GeneratedSong, outputDir, overridesFile string, require policy.Policy) ([]*models.Song, error) {
+	pinned, err := overrides.Load(overridesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides: %w", err)
+	}
+	report := &overrides.Report{}
+	defer report.Write(os.Stdout, pinned)
+
+	var printed []*models.Song
+	verified, unreadable := 0, 0
+
+	for i, genSong := range genSongs {
+		pinned.Apply(&genSong, report)
+		if genSong.Excluded {
+			continue
+		}
+		if err := require.Check(&genSong); err != nil {
+			fmt.Printf("[%d/%d] Skipping %s: %v\n", i+1, len(genSongs), genSong.Title, err)
+			continue
+		}
+
+		fmt.Printf("[%d/%d] Generating assets for %s...\n", i+1, len(genSongs), genSong.Title)
+
+		song := cardSong(&genSong, require)
+
+		// F. Generate Assets
+		// 1. QR Code
+		q, err := r.createQRCode(song)
+		if err != nil {
+			fmt.Printf("  -> Failed to generate QR code: %v\n", err)
+			continue
+		}
+
+		// 2. Card Front
+		if err := r.generateCardFront(song, outputDir); err != nil {
+			fmt.Printf("  -> Failed to generate Card Front: %v\n", err)
+			continue
+		}
+
+		// 3. Card Back
+		if err := r.generateCardBack(song, q, outputDir); err != nil {
+			fmt.Printf("  -> Failed to generate Card Back: %v\n", err)
+			continue
+		}
+
+		// 4. Scan the backs
+		if r.verify {
+			if err := r.verifyCardBack(song, outputDir); err != nil {
+				fmt.Printf("  -> Failed to verify Card Back: %v\n", err)
+				if err := r.rejectCard(song, outputDir); err != nil {
+					return nil, err
+				}
+				unreadable++
+				continue
+			}
+			verified++
+		}
+		printed = append(printed, song)
+	}
+	if r.verify {
+		fmt.Printf("Verified %d card backs, %d failed\n", verified, unreadable)
+	}
+	if unreadable > 0 {
+		return nil, fmt.Errorf("%d card backs failed to verify, their cards were moved to %s", unreadable, filepath.Join(outputDir, outDirRejectedName))
+	}
+	return printed, nil
+}
+
+// cardSong converts a summary entry to the song its card is drawn from.
+// Only the links the policy accepts are encoded, so that a card never
+// points at a link that failed validation or, unless allowed, could not be
+// checked.
+func cardSong(genSong *models.GeneratedSong, require policy.Policy) *models.Song {
+	song := &models.Song{
+		Title:        genSong.Title,
+		Artists:      genSong.Artists,
+		Year:         genSong.Year,
+		Explicit:     genSong.Explicit,
+		Genre:        genSong.Genre,
+		ThumbnailURL: genSong.ThumbnailURL,
+	}
+	for _, p := range platform.All() {
+		if require.Usable(genSong, p.Key()) {
+			song.SetLink(p.Key(), p.ParseID(genSong.Link(p.Key())))
+		}
+	}
+	return song
+}
+
+// songs returns the looked up songs and where they were read from.
+func (opts Options) songs() ([]models.GeneratedSong, string, error) {
+	if opts.Catalog == "" {
+		songs, err := readGeneratedSongs(opts.InputFile)
+		return songs, opts.InputFile, err
+	}
+	cat, err := catalog.Open(opts.Catalog)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cat.Close()
+	songs, err := cat.Summaries(opts.Deck)
+	return songs, "deck " + opts.Deck, err
+}
+
+// recordPrint adds the songs printed to the catalogue as a print run.
+func recordPrint(opts Options, printed []*models.Song) error {
+	cat, err := catalog.Open(opts.Catalog)
+	if err != nil {
+		return err
+	}
+	defer cat.Close()
+	p := &catalog.Print{Deck: opts.Deck, Output: opts.OutputDir, Format: opts.Card.Format}
+	for _, s := range printed {
+		p.Songs = append(p.Songs, s.Spotify)
+	}
+	if err := cat.AddPrint(p); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded print run %d of deck %s with %d cards\n", p.ID, p.Deck, len(p.Songs))
+	return nil
+}
+
+func readGeneratedSongs(path string) ([]models.GeneratedSong, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var songs []models.GeneratedSong
+	if err := json.NewDecoder(f).Decode(&songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
@@ -0,0 +1,38 @@
+package generate
+
+import (
+	"testing"
+
+	"temporalize/internal/models"
+	"temporalize/internal/policy"
+)
+
+func TestCardSongUsableLinks(t *testing.T) {
+	g := &models.GeneratedSong{
+		Title:      "Don't You (Forget About Me)",
+		Year:       1985,
+		Spotify:    "https://open.spotify.com/track/3fH4KjXFYMmljxrcGrbPj9",
+		Deezer:     "https://www.deezer.com/track/3135556",
+		Tidal:      "https://tidal.com/browse/track/1234",
+		AppleMusic: "https://music.apple.com/us/album/_/1440832700?i=1440832755",
+		Platforms: map[string]models.PlatformStatus{
+			models.PlatformSpotify:    {Status: models.StatusOK},
+			models.PlatformDeezer:     {Status: models.StatusFixed},
+			models.PlatformTidal:      {Status: models.StatusUnverified},
+			models.PlatformAppleMusic: {Status: models.StatusFailed},
+		},
+	}
+
+	song := cardSong(g, policy.Policy{})
+	if song.Spotify != "3fH4KjXFYMmljxrcGrbPj9" || song.Deezer != "3135556" {
+		t.Errorf("verified links = %q, %q, want their IDs", song.Spotify, song.Deezer)
+	}
+	if song.Tidal != "" || song.AppleMusic != "" {
+		t.Errorf("card encodes unverified %q and failed %q links", song.Tidal, song.AppleMusic)
+	}
+
+	song = cardSong(g, policy.Policy{AllowUnverified: true})
+	if song.Tidal != "1234" || song.AppleMusic != "" {
+		t.Errorf("with unverified links allowed, Tidal = %q and Apple Music = %q, want only Tidal", song.Tidal, song.AppleMusic)
+	}
+}
@@ -0,0 +1,399 @@
+package generate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"temporalize/internal/config"
+	"temporalize/internal/genre"
+	"temporalize/internal/models"
+
+	"github.com/fogleman/gg"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	outDirStdFrontName  = "cards/front/standard"
+	outDirStdBackName   = "cards/back/standard"
+	outDirMiniFrontName = "cards/front/usmini"
+	outDirMiniBackName  = "cards/back/usmini"
+
+	baseFontSize    = 30.0
+	lineSpacing     = 1.1
+	borderThickness = 0.06
+	cornerRadius    = 0.125
+)
+
+// renderer draws card faces with the configured geometry, fonts and genre
+// themes.
+type renderer struct {
+	card         config.Card
+	genres       *genre.Taxonomy
+	thumbnailDir string
+	// format is the card file format, see config.CardFormats.
+	format string
+	// verify scans each back after writing it.
+	verify bool
+	fonts  map[string]*cardFont
+}
+
+// font returns the font at path, loading it on first use.
+func (r *renderer) font(path string) (*cardFont, error) {
+	if f, ok := r.fonts[path]; ok {
+		return f, nil
+	}
+	f, err := loadFont(path)
+	if err != nil {
+		return nil, err
+	}
+	if r.fonts == nil {
+		r.fonts = make(map[string]*cardFont)
+	}
+	r.fonts[path] = f
+	return f, nil
+}
+
+// qrLevels maps config.QRLevels to the encoder's recovery levels.
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// createQRCode encodes the song's links at the configured level.
+func (r *renderer) createQRCode(s *models.Song) (qrSymbol, error) {
+	payload, err := songPayload(s)
+	if err != nil {
+		return qrSymbol{}, fmt.Errorf("failed to build qr payload: %w", err)
+	}
+
+	qrBytes, err := compress(payload)
+	if err != nil {
+		return qrSymbol{}, fmt.Errorf("failed to compress qr data: %w", err)
+	}
+
+	qr := r.card.QR
+	level, ok := qrLevels[qr.Level]
+	if !ok {
+		return qrSymbol{}, fmt.Errorf("unknown qr level %q, want one of %v", qr.Level, config.QRLevels)
+	}
+	q, err := qrcode.New(string(qrBytes), level)
+	if err != nil {
+		return qrSymbol{}, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	q.DisableBorder = true
+	return qrSymbol{bitmap: q.Bitmap(), quiet: qr.QuietZone, dark: qr.Dark, light: qr.Light, version: q.VersionNumber}, nil
+}
+
+func (r *renderer) generateCardFront(s *models.Song, outputDir string) error {
+	stdDir := filepath.Join(outputDir, outDirStdFrontName)
+	miniDir := filepath.Join(outputDir, outDirMiniFrontName)
+
+	if err := os.MkdirAll(stdDir, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(miniDir, 0755); err != nil {
+		return err
+	}
+
+	if err := r.drawFront(s, r.card.Standard.Width, r.card.Standard.Height, stdDir); err != nil {
+		return err
+	}
+	return r.drawFront(s, r.card.USMini.Width, r.card.USMini.Height, miniDir)
+}
+
+func (r *renderer) drawFront(s *models.Song, widthIn, heightIn float64, outDir string) error {
+	dpi, bleed, margin := r.card.DPI, r.card.Bleed, r.card.Margin
+	totalWidth := int((widthIn + 2*bleed) * dpi)
+	totalHeight := int((heightIn + 2*bleed) * dpi)
+
+	theme := r.genres.Theme(s.Genre)
+
+	dc, err := newCanvas(r.format, totalWidth, totalHeight, dpi)
+	if err != nil {
+		return err
+	}
+	dc.Clear(theme.Dark)
+
+	thumbPath := filepath.Join(r.thumbnailDir, s.FileName()+".jpeg")
+	img, err := gg.LoadImage(thumbPath)
+	if err != nil {
+		return fmt.Errorf("failed to load thumbnail %s: %w", thumbPath, err)
+	}
+
+	safeX := (bleed + margin) * dpi
+	safeY := (bleed + margin) * dpi
+	safeW := (widthIn - 2*margin) * dpi
+	safeH := (heightIn - 2*margin) * dpi
+
+	borderPx := borderThickness * dpi
+	effRadius := cornerRadius
+	if widthIn >= r.card.Standard.Width {
+		effRadius = 0.165
+	}
+	radiusPx := effRadius * dpi
+	iconColWidth := int(radiusPx * 2)
+
+	scaleFactor := widthIn / r.card.USMini.Width
+	scaledFontSize := baseFontSize * scaleFactor
+	yearFontSize := scaledFontSize * 3.5
+	textFontSize := scaledFontSize * 1.0
+
+	// headerH := float64(yearFontSize) * 1.2
+	// footerH := headerH
+	// textRowH := float64(textFontSize) * 1.5
+
+	minHeaderH := float64(yearFontSize) * 1.5
+	minFooterH := float64(textFontSize) * 3.5
+	availHForArt := safeH - (minHeaderH + minFooterH)
+
+	artSize := safeW
+	if availHForArt < artSize {
+		artSize = availHForArt
+	}
+	if artSize < 0 {
+		artSize = 0
+	}
+
+	artTopY := (float64(totalHeight) - artSize) / 2
+	artBottomY := artTopY + artSize
+	headerY := (safeY + artTopY) / 2
+	safeBottomY := safeY + safeH
+	footerY := (artBottomY + safeBottomY) / 2
+
+	artX := (float64(totalWidth) - artSize) / 2
+	dc.FillRoundedRect(artX, artTopY, artSize, artSize, radiusPx, theme.Light)
+
+	innerArtSize := artSize - 2*borderPx
+	if innerArtSize > 0 {
+		innerRadius := radiusPx - borderPx
+		if innerRadius < 0 {
+			innerRadius = 0
+		}
+		dc.DrawImage(img, artX+artSize/2, artTopY+artSize/2, innerArtSize, innerArtSize, innerRadius)
+	}
+
+	fntBold, err := r.font(r.card.TitleFont)
+	if err != nil {
+		return err
+	}
+	dc.SetFont(fntBold, yearFontSize)
+	yearStr := fmt.Sprintf("%d", s.Year)
+	centerX := float64(totalWidth) / 2
+	yearTextNudge := yearFontSize * 0.1
+	dc.DrawString(yearStr, centerX, headerY-yearTextNudge, theme.Light, false)
+
+	genreIconSize := int(yearFontSize * 0.85)
+	if theme.Icon != "" {
+		imgGenre, err := loadIcon(theme.Icon, theme.Light)
+		if err == nil {
+			dc.DrawImage(imgGenre, safeX+float64(iconColWidth)/2, headerY, iconWidth(imgGenre, genreIconSize), float64(genreIconSize), 0)
+		}
+	}
+
+	if s.Explicit {
+		explicitImg, err := gg.LoadImage(r.card.ExplicitIcon)
+		if err == nil {
+			targetW := int(yearFontSize * 0.85)
+			bounds := explicitImg.Bounds()
+			ratio := float64(bounds.Dx()) / float64(bounds.Dy())
+			targetH := int(float64(targetW) / ratio)
+			topRightCenterX := safeX + safeW - float64(iconColWidth)/2
+			dc.DrawImage(explicitImg, topRightCenterX, headerY, float64(targetW), float64(targetH), 0)
+		}
+	}
+
+	fntRegular, err := r.font(r.card.TextFont)
+	if err != nil {
+		return err
+	}
+	dc.SetFont(fntRegular, textFontSize)
+
+	titleTextNudge := textFontSize * 0.1
+
+	drawTextRow := func(text string, iconPath string, yPos float64) float64 {
+		iconImg, err := loadIcon(iconPath, theme.Light)
+		if err != nil {
+			log.Printf("Failed to load icon %s: %v", iconPath, err)
+			return 0
+		}
+		iconW := iconWidth(iconImg, int(textFontSize))
+		gap := textFontSize * 0.5
+		maxTextW := safeW - (iconW + gap)
+		lines := dc.WordWrap(text, maxTextW)
+		lineH := textFontSize * lineSpacing
+		textBlockH := float64(len(lines)) * lineH
+		maxLineW := 0.0
+		for _, line := range lines {
+			w := dc.MeasureString(line)
+			if w > maxLineW {
+				maxLineW = w
+			}
+		}
+		totalRowW := iconW + gap + maxLineW
+		startX := (float64(totalWidth) - totalRowW) / 2
+		dc.DrawImage(iconImg, startX+iconW/2, yPos, iconW, float64(int(textFontSize)), 0)
+		firstLineY := yPos - textBlockH/2 + lineH/2
+		textCenterX := startX + iconW + gap + maxLineW/2
+		for i, line := range lines {
+			lineY := firstLineY + float64(i)*lineH
+			dc.DrawString(line, textCenterX, lineY-titleTextNudge, theme.Light, false)
+		}
+		return textBlockH
+	}
+
+	measureHeight := func(text string, iconSize float64) float64 {
+		maxTextW := safeW - (iconSize + textFontSize*0.5)
+		lines := dc.WordWrap(text, maxTextW)
+		return float64(len(lines)) * textFontSize * lineSpacing
+	}
+
+	titleH := measureHeight(s.Title, textFontSize)
+	artistH := measureHeight(strings.Join(s.Artists, ", "), textFontSize)
+	blockGap := textFontSize * 0.5
+	totalFooterContentH := titleH + blockGap + artistH
+	footerContentTopY := footerY - totalFooterContentH/2
+	titleCenterY := footerContentTopY + titleH/2
+	artistCenterY := footerContentTopY + titleH + blockGap + artistH/2
+
+	drawTextRow(s.Title, r.card.SongIcon, titleCenterY)
+	drawTextRow(strings.Join(s.Artists, ", "), r.card.ArtistIcon, artistCenterY)
+
+	return dc.Save(filepath.Join(outDir, frontFileName(s, r.format)))
+}
+
+func (r *renderer) generateCardBack(s *models.Song, q qrSymbol, outputDir string) error {
+	stdDir := filepath.Join(outputDir, outDirStdBackName)
+	miniDir := filepath.Join(outputDir, outDirMiniBackName)
+
+	if err := os.MkdirAll(stdDir, 0755); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(miniDir, 0755); err != nil {
+		return err
+	}
+
+	if err := r.drawBack(q, r.card.Standard.Width, r.card.Standard.Height, filepath.Join(stdDir, backFileName(s, r.format))); err != nil {
+		return err
+	}
+	return r.drawBack(q, r.card.USMini.Width, r.card.USMini.Height, filepath.Join(miniDir, backFileName(s, r.format)))
+}
+
+func frontFileName(s *models.Song, format string) string {
+	return fmt.Sprintf("%s-%s.%s", s.FileName(), s.Genre, format)
+}
+
+func backFileName(s *models.Song, format string) string {
+	return s.FileName() + "." + format
+}
+
+func (r *renderer) drawBack(q qrSymbol, widthIn, heightIn float64, outPath string) error {
+	dpi, bleed, margin := r.card.DPI, r.card.Bleed, r.card.Margin
+	totalWidth := int((widthIn + 2*bleed) * dpi)
+	totalHeight := int((heightIn + 2*bleed) * dpi)
+
+	dc, err := newCanvas(r.format, totalWidth, totalHeight, dpi)
+	if err != nil {
+		return err
+	}
+	dc.Clear(r.card.BackColor)
+
+	safeW := (widthIn - 2*margin) * dpi
+	safeH := (heightIn - 2*margin) * dpi
+	// Whole pixel modules keep every edge sharp.
+	module := int(min(safeW, safeH)) / q.size()
+	if minModule := r.card.QR.MinModule * dpi; float64(module) < max(minModule, 1) {
+		need := float64(q.size()) * math.Ceil(max(minModule, 1)) / dpi
+		return fmt.Errorf("a version %d level %s qr code is %d modules wide with its quiet zone, %.2fin at %vin a module, more than the %.2fin safe area of a %vx%vin card",
+			q.version, r.card.QR.Level, q.size(), need, r.card.QR.MinModule, min(safeW, safeH)/dpi, widthIn, heightIn)
+	}
+	qrSize := module * q.size()
+
+	cX := totalWidth / 2
+	cY := totalHeight / 2
+	qrX := cX - qrSize/2
+	qrY := cY - qrSize/2
+	dc.DrawQR(q, float64(qrX), float64(qrY), float64(module))
+
+	font, err := r.font(r.card.TitleFont)
+	if err != nil {
+		return err
+	}
+	dc.SetFont(font, float64(totalWidth)*0.12)
+	text := "Temporalize"
+
+	topTextY := float64(qrY) / 2.0
+	dc.DrawString(text, float64(cX), topTextY, color.White, false)
+
+	bottomTextY := float64(qrY+qrSize+totalHeight) / 2.0
+	dc.DrawString(text, float64(cX), bottomTextY, color.White, true)
+
+	return dc.Save(outPath)
+}
+
+// Helpers
+
+func resizeImage(img image.Image, w, h int) image.Image {
+	dc := gg.NewContext(w, h)
+	sx := float64(w) / float64(img.Bounds().Dx())
+	sy := float64(h) / float64(img.Bounds().Dy())
+	dc.Scale(sx, sy)
+	dc.DrawImage(img, 0, 0)
+	return dc.Image()
+}
+
+func loadIcon(path string, tint color.Color) (image.Image, error) {
+	img, err := gg.LoadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	return tintIcon(img, tint), nil
+}
+
+// iconWidth returns the width of img scaled to h pixels high.
+func iconWidth(img image.Image, h int) float64 {
+	bounds := img.Bounds()
+	ratio := float64(bounds.Dx()) / float64(bounds.Dy())
+	return float64(int(float64(h) * ratio))
+}
+
+func tintIcon(img image.Image, tint color.Color) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	tr, tg, tb, ta := tint.RGBA()
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			_, _, _, a := c.RGBA()
+			r, g, b, _ := c.RGBA()
+			lum := 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			lumNorm := lum / 65535.0
+			maskAlpha := 1.0 - lumNorm
+			if maskAlpha < 0 {
+				maskAlpha = 0
+			}
+			originalAlphaNorm := float64(a) / 65535.0
+			finalAlphaNorm := maskAlpha * originalAlphaNorm
+			newA := uint32(finalAlphaNorm * float64(ta))
+			newR := uint32(float64(tr) * finalAlphaNorm)
+			newG := uint32(float64(tg) * finalAlphaNorm)
+			newB := uint32(float64(tb) * finalAlphaNorm)
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(newR),
+				G: uint16(newG),
+				B: uint16(newB),
+				A: uint16(newA),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,103 @@
+package generate
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+
+	"temporalize/internal/pdf"
+)
+
+// pdfCanvas draws a single page PDF face sized in inches, with its fonts
+// embedded.
+type pdfCanvas struct {
+	textMetrics
+	width  float64
+	height float64
+	buf    bytes.Buffer
+	doc    *pdf.Writer
+	page   *pdf.Page
+	fonts  map[*cardFont]*pdf.Font
+	err    error
+}
+
+func newPDFCanvas(width, height int, dpi float64) *pdfCanvas {
+	c := &pdfCanvas{textMetrics: newTextMetrics(), width: float64(width), height: float64(height), fonts: make(map[*cardFont]*pdf.Font)}
+	c.doc = pdf.NewWriter(&c.buf)
+	k := pdf.PointsPerInch / dpi
+	c.page = c.doc.NewPage(float64(width)*k, float64(height)*k)
+	c.page.Transform(pdf.Matrix{k, 0, 0, k, 0, 0})
+	return c
+}
+
+// rect converts a box from the top left to PDF's bottom left origin.
+func (c *pdfCanvas) rect(x, y, w, h float64) pdf.Rect {
+	return pdf.Rect{X: x, Y: c.height - y - h, W: w, H: h}
+}
+
+func (c *pdfCanvas) Clear(col color.Color) {
+	c.page.SetColor(col)
+	c.page.RoundedRect(c.rect(0, 0, c.width, c.height), 0)
+	c.page.Fill()
+}
+
+func (c *pdfCanvas) FillRoundedRect(x, y, w, h, radius float64, col color.Color) {
+	c.page.SetColor(col)
+	c.page.RoundedRect(c.rect(x, y, w, h), radius)
+	c.page.Fill()
+}
+
+func (c *pdfCanvas) DrawImage(img image.Image, cx, cy, w, h, radius float64) {
+	r := c.rect(cx-w/2, cy-h/2, w, h)
+	if radius > 0 {
+		c.page.Push()
+		defer c.page.Pop()
+		c.page.RoundedRect(r, radius)
+		c.page.Clip()
+	}
+	c.page.DrawImage(img, r)
+}
+
+func (c *pdfCanvas) DrawString(s string, x, y float64, col color.Color, rotated bool) {
+	f, ok := c.fonts[c.font]
+	if !ok {
+		var err error
+		if f, err = c.doc.EmbedFont(c.font.data); err != nil {
+			c.err = err
+			return
+		}
+		c.fonts[c.font] = f
+	}
+
+	bx, by := c.baseline(s, x, y)
+	m := pdf.Matrix{1, 0, 0, 1, bx, c.height - by}
+	if rotated {
+		// The baseline rotated half a turn about x, y.
+		m = pdf.Matrix{-1, 0, 0, -1, 2*x - bx, c.height - (2*y - by)}
+	}
+	c.page.SetColor(col)
+	if err := c.page.DrawText(f, c.size, m, s); err != nil && c.err == nil {
+		c.err = err
+	}
+}
+
+func (c *pdfCanvas) DrawQR(q qrSymbol, x, y, module float64) {
+	size := float64(q.size()) * module
+	c.FillRoundedRect(x, y, size, size, 0, q.light)
+	c.page.SetColor(q.dark)
+	for _, r := range q.runs() {
+		c.page.RoundedRect(c.rect(x+float64(r.col)*module, y+float64(r.row)*module, float64(r.length)*module, module), 0)
+	}
+	c.page.Fill()
+}
+
+func (c *pdfCanvas) Save(path string) error {
+	if c.err != nil {
+		return c.err
+	}
+	if err := c.doc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, c.buf.Bytes(), 0644)
+}
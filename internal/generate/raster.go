@@ -0,0 +1,94 @@
+package generate
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+// rasterCanvas draws a PNG face with gg.
+type rasterCanvas struct {
+	dc *gg.Context
+}
+
+func newRasterCanvas(width, height int) *rasterCanvas {
+	return &rasterCanvas{dc: gg.NewContext(width, height)}
+}
+
+func (c *rasterCanvas) Clear(col color.Color) {
+	c.dc.SetColor(col)
+	c.dc.Clear()
+}
+
+func (c *rasterCanvas) FillRoundedRect(x, y, w, h, radius float64, col color.Color) {
+	c.dc.SetColor(col)
+	c.dc.DrawRoundedRectangle(x, y, w, h, radius)
+	c.dc.Fill()
+}
+
+func (c *rasterCanvas) DrawImage(img image.Image, cx, cy, w, h, radius float64) {
+	if radius > 0 {
+		c.dc.Push()
+		c.dc.DrawRoundedRectangle(cx-w/2, cy-h/2, w, h, radius)
+		c.dc.Clip()
+		defer func() {
+			c.dc.ResetClip()
+			c.dc.Pop()
+		}()
+	}
+	c.dc.DrawImageAnchored(resizeImage(img, int(w), int(h)), int(cx), int(cy), 0.5, 0.5)
+}
+
+func (c *rasterCanvas) SetFont(f *cardFont, size float64) {
+	c.dc.SetFontFace(truetype.NewFace(f.ttf, &truetype.Options{Size: size}))
+}
+
+func (c *rasterCanvas) MeasureString(s string) float64 {
+	w, _ := c.dc.MeasureString(s)
+	return w
+}
+
+func (c *rasterCanvas) WordWrap(s string, width float64) []string {
+	return c.dc.WordWrap(s, width)
+}
+
+func (c *rasterCanvas) DrawString(s string, x, y float64, col color.Color, rotated bool) {
+	c.dc.SetColor(col)
+	if rotated {
+		c.dc.Push()
+		defer c.dc.Pop()
+		c.dc.RotateAbout(gg.Radians(180), x, y)
+	}
+	c.dc.DrawStringAnchored(s, x, y, 0.5, 0.5)
+}
+
+// DrawQR fills whole pixels without antialiasing, so x, y and module
+// should be integers for every module edge to be sharp.
+func (c *rasterCanvas) DrawQR(q qrSymbol, x, y, module float64) {
+	dst := c.dc.Image().(*image.RGBA)
+	x0, y0, m := int(x), int(y), int(module)
+	size := q.size() * m
+	draw.Draw(dst, image.Rect(x0, y0, x0+size, y0+size), image.NewUniform(q.light), image.Point{}, draw.Src)
+	dark := image.NewUniform(q.dark)
+	for _, r := range q.runs() {
+		rx, ry := x0+r.col*m, y0+r.row*m
+		draw.Draw(dst, image.Rect(rx, ry, rx+r.length*m, ry+m), dark, image.Point{}, draw.Src)
+	}
+}
+
+func (c *rasterCanvas) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, c.dc.Image()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
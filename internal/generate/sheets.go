@@ -0,0 +1,61 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"temporalize/internal/config"
+	"temporalize/internal/models"
+	"temporalize/internal/sheets"
+)
+
+const outDirSheetsName = "sheets"
+
+// writeSheets imposes the printed cards of each size onto a PDF in
+// outputDir/sheets.
+func (r *renderer) writeSheets(songs []*models.Song, outputDir string, opts sheets.Options) error {
+	dir := filepath.Join(outputDir, outDirSheetsName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, size := range []struct {
+		name              string
+		size              config.Size
+		frontDir, backDir string
+	}{
+		{"standard", r.card.Standard, outDirStdFrontName, outDirStdBackName},
+		{"usmini", r.card.USMini, outDirMiniFrontName, outDirMiniBackName},
+	} {
+		cards := make([]sheets.Card, len(songs))
+		for i, s := range songs {
+			cards[i] = sheets.Card{
+				Front: filepath.Join(outputDir, size.frontDir, frontFileName(s, r.format)),
+				Back:  filepath.Join(outputDir, size.backDir, backFileName(s, r.format)),
+			}
+		}
+		opts.Width, opts.Height, opts.Bleed = size.size.Width, size.size.Height, r.card.Bleed
+
+		path := filepath.Join(dir, size.name+".pdf")
+		n, err := writeSheetFile(path, opts, cards)
+		if err != nil {
+			return fmt.Errorf("failed to write %s sheets: %w", size.name, err)
+		}
+		fmt.Printf("Wrote %d %s sheets to %s\n", n, size.name, path)
+	}
+	return nil
+}
+
+func writeSheetFile(path string, opts sheets.Options, cards []sheets.Card) (int, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := sheets.Write(f, opts, cards)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	return n, f.Close()
+}
@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// svgCanvas draws an SVG face sized in inches, with its fonts and images
+// embedded as data URLs.
+type svgCanvas struct {
+	textMetrics
+	width, height int
+	dpi           float64
+	body          bytes.Buffer
+	// fonts are the fonts drawn with; fonts[i] is font-family fonti.
+	fonts []*cardFont
+	clips int
+	// err is the first drawing error, returned by Save.
+	err error
+}
+
+func newSVGCanvas(width, height int, dpi float64) *svgCanvas {
+	return &svgCanvas{textMetrics: newTextMetrics(), width: width, height: height, dpi: dpi}
+}
+
+func (c *svgCanvas) Clear(col color.Color) {
+	fmt.Fprintf(&c.body, `<rect width="%d" height="%d"%s/>`+"\n", c.width, c.height, svgFill(col))
+}
+
+func (c *svgCanvas) FillRoundedRect(x, y, w, h, radius float64, col color.Color) {
+	fmt.Fprintf(&c.body, `<rect x="%s" y="%s" width="%s" height="%s" rx="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgNum(radius), svgFill(col))
+}
+
+func (c *svgCanvas) DrawImage(img image.Image, cx, cy, w, h, radius float64) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		if c.err == nil {
+			c.err = fmt.Errorf("failed to encode image: %w", err)
+		}
+		return
+	}
+	x, y := cx-w/2, cy-h/2
+	clip := ""
+	if radius > 0 {
+		c.clips++
+		fmt.Fprintf(&c.body, `<clipPath id="clip%d"><rect x="%s" y="%s" width="%s" height="%s" rx="%s"/></clipPath>`+"\n", c.clips, svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgNum(radius))
+		clip = fmt.Sprintf(` clip-path="url(#clip%d)"`, c.clips)
+	}
+	fmt.Fprintf(&c.body, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="none"%s xlink:href="data:image/png;base64,%s"/>`+"\n",
+		svgNum(x), svgNum(y), svgNum(w), svgNum(h), clip, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+func (c *svgCanvas) DrawString(s string, x, y float64, col color.Color, rotated bool) {
+	family := -1
+	for i, f := range c.fonts {
+		if f == c.font {
+			family = i
+		}
+	}
+	if family < 0 {
+		family = len(c.fonts)
+		c.fonts = append(c.fonts, c.font)
+	}
+
+	bx, by := c.baseline(s, x, y)
+	transform := ""
+	if rotated {
+		transform = fmt.Sprintf(` transform="rotate(180 %s %s)"`, svgNum(x), svgNum(y))
+	}
+	fmt.Fprintf(&c.body, `<text x="%s" y="%s" font-family="font%d" font-size="%s"%s%s>`, svgNum(bx), svgNum(by), family, svgNum(c.size), svgFill(col), transform)
+	xml.EscapeText(&c.body, []byte(s))
+	c.body.WriteString("</text>\n")
+}
+
+func (c *svgCanvas) DrawQR(q qrSymbol, x, y, module float64) {
+	size := float64(q.size()) * module
+	fmt.Fprintf(&c.body, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(size), svgNum(size), svgFill(q.light))
+	fmt.Fprintf(&c.body, `<path%s shape-rendering="crispEdges" d="`, svgFill(q.dark))
+	for _, r := range q.runs() {
+		fmt.Fprintf(&c.body, "M%s %sh%sv%sh-%sz", svgNum(x+float64(r.col)*module), svgNum(y+float64(r.row)*module), svgNum(float64(r.length)*module), svgNum(module), svgNum(float64(r.length)*module))
+	}
+	c.body.WriteString(`"/>` + "\n")
+}
+
+func (c *svgCanvas) Save(path string) error {
+	if c.err != nil {
+		return c.err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%sin" height="%sin" viewBox="0 0 %d %d">`+"\n",
+		svgNum(float64(c.width)/c.dpi), svgNum(float64(c.height)/c.dpi), c.width, c.height)
+	if len(c.fonts) > 0 {
+		buf.WriteString("<defs><style>\n")
+		for i, f := range c.fonts {
+			fmt.Fprintf(&buf, "@font-face { font-family: font%d; src: url(data:font/ttf;base64,%s); }\n", i, base64.StdEncoding.EncodeToString(f.data))
+		}
+		buf.WriteString("</style></defs>\n")
+	}
+	buf.Write(c.body.Bytes())
+	buf.WriteString("</svg>\n")
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// svgFill returns the fill attributes for col.
+func svgFill(col color.Color) string {
+	n := color.NRGBAModel.Convert(col).(color.NRGBA)
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+	if n.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%s"`, svgNum(float64(n.A)/0xff))
+	}
+	return fill
+}
+
+func svgNum(f float64) string {
+	return fmt.Sprintf("%.6g", f)
+}
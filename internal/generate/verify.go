@@ -0,0 +1,168 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+	"temporalize/internal/overrides"
+	"temporalize/internal/policy"
+
+	"github.com/tuotoo/qrcode"
+)
+
+// scanQR reads the QR code in an image file the way a phone would. Tests
+// replace it.
+var scanQR = func(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := qrcode.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(m.Content), nil
+}
+
+// verifyBack scans the back image at path and checks that it decodes to p.
+func verifyBack(path string, p codec.CardPayload) error {
+	data, err := scanQR(path)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	if err := verifyCompression(data, p); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// verifyCardBack scans both sizes of the song's back.
+func (r *renderer) verifyCardBack(s *models.Song, outputDir string) error {
+	payload, err := songPayload(s)
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{outDirStdBackName, outDirMiniBackName} {
+		if err := verifyBack(filepath.Join(outputDir, dir, backFileName(s, r.format)), payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outDirRejectedName holds the faces of cards whose backs failed to verify,
+// in the same layout as the cards they were moved from.
+const outDirRejectedName = "rejected"
+
+// rejectCard moves both faces of both sizes of the song's card to
+// outputDir/rejected, so every card left in the output directory scans.
+func (r *renderer) rejectCard(s *models.Song, outputDir string) error {
+	for _, f := range []struct{ dir, name string }{
+		{outDirStdFrontName, frontFileName(s, r.format)},
+		{outDirMiniFrontName, frontFileName(s, r.format)},
+		{outDirStdBackName, backFileName(s, r.format)},
+		{outDirMiniBackName, backFileName(s, r.format)},
+	} {
+		dst := filepath.Join(outputDir, outDirRejectedName, f.dir, f.name)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(outputDir, f.dir, f.name), dst); err != nil {
+			return fmt.Errorf("failed to reject card: %w", err)
+		}
+	}
+	fmt.Printf("  -> Moved the card to %s\n", filepath.Join(outputDir, outDirRejectedName))
+	return nil
+}
+
+// Verify scans every PNG card back in opts.OutputDir. Backs of songs in the
+// summary must decode to their links, any others to a valid payload.
+func Verify(opts Options) error {
+	cardPolicy, err := policy.Parse(opts.Require)
+	if err != nil {
+		return err
+	}
+	cardPolicy.AllowUnverified = opts.AllowUnverified
+
+	genSongs, input, err := opts.songs()
+	if err != nil {
+		return fmt.Errorf("failed to read generated songs: %w", err)
+	}
+	pinned, err := overrides.Load(opts.OverridesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read overrides: %w", err)
+	}
+	payloads := make(map[string]codec.CardPayload)
+	for _, genSong := range genSongs {
+		pinned.Apply(&genSong, &overrides.Report{})
+		if genSong.Excluded {
+			continue
+		}
+		song := cardSong(&genSong, cardPolicy)
+		p, err := songPayload(song)
+		if err != nil {
+			continue
+		}
+		payloads[backFileName(song, "png")] = p
+	}
+
+	var paths []string
+	for _, dir := range []string{outDirStdBackName, outDirMiniBackName} {
+		matches, err := filepath.Glob(filepath.Join(opts.OutputDir, dir, "*.png"))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no png card backs in %s", opts.OutputDir)
+	}
+	sort.Strings(paths)
+
+	failed, unknown := 0, 0
+	for _, path := range paths {
+		p, ok := payloads[filepath.Base(path)]
+		if ok {
+			err = verifyBack(path, p)
+		} else {
+			unknown++
+			err = decodeBack(path)
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL %v\n", err)
+		}
+	}
+
+	fmt.Printf("Verified %d card backs: %d ok, %d failed", len(paths), len(paths)-failed, failed)
+	if unknown > 0 {
+		fmt.Printf(" (%d not in %s, checked for a valid payload only)", unknown, input)
+	}
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d card backs failed to verify", failed, len(paths))
+	}
+	return nil
+}
+
+// decodeBack scans the back image at path and checks that it holds a
+// payload.
+func decodeBack(path string) error {
+	data, err := scanQR(path)
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	var p codec.CardPayload
+	if err := p.Decode(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if p == (codec.CardPayload{Version: p.Version, Explicit: p.Explicit}) {
+		return fmt.Errorf("%s: payload has no links", path)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/config"
+	"temporalize/internal/models"
+	"temporalize/internal/policy"
+)
+
+// fakeScan makes scanQR return the payload stored for each path.
+func fakeScan(t *testing.T, scans map[string][]byte) {
+	t.Helper()
+	orig := scanQR
+	t.Cleanup(func() { scanQR = orig })
+	scanQR = func(path string) ([]byte, error) {
+		data, ok := scans[filepath.Base(path)]
+		if !ok {
+			return nil, errors.New("no qr code found")
+		}
+		return data, nil
+	}
+}
+
+func encode(t *testing.T, p codec.CardPayload) []byte {
+	t.Helper()
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifyCardBack(t *testing.T) {
+	r := &renderer{format: "png"}
+	song := &models.Song{Title: "Take On Me", Year: 1985, Spotify: "2WfaOiMkCvy7F5fcp2zZ8L"}
+	name := backFileName(song, "png")
+
+	fakeScan(t, map[string][]byte{name: encode(t, codec.CardPayload{Spotify: song.Spotify})})
+	if err := r.verifyCardBack(song, t.TempDir()); err != nil {
+		t.Errorf("verifyCardBack = %v", err)
+	}
+
+	fakeScan(t, map[string][]byte{name: encode(t, codec.CardPayload{Spotify: "4iV5W9uYEdYUVa79Axb7Rh"})})
+	if err := r.verifyCardBack(song, t.TempDir()); err == nil || !strings.Contains(err.Error(), "Spotify mismatch") {
+		t.Errorf("verifyCardBack of another song's code = %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	songs := []models.GeneratedSong{
+		{Title: "Take On Me", Year: 1985, Spotify: "2WfaOiMkCvy7F5fcp2zZ8L"},
+		{Title: "Money for Nothing", Year: 1985, Spotify: "4iV5W9uYEdYUVa79Axb7Rh"},
+	}
+	data, err := json.Marshal(songs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "lookup.json")
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range []string{outDirStdBackName, outDirMiniBackName} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"1985-Take On Me.png", "1985-Money for Nothing.png", "1999-Stale.png"} {
+			if err := os.WriteFile(filepath.Join(dir, sub, name), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	opts := Options{InputFile: input, OutputDir: dir, OverridesFile: filepath.Join(dir, "overrides.json"), Require: policy.Default}
+
+	scans := map[string][]byte{
+		"1985-Take On Me.png":        encode(t, codec.CardPayload{Spotify: songs[0].Spotify}),
+		"1985-Money for Nothing.png": encode(t, codec.CardPayload{Spotify: songs[1].Spotify}),
+		"1999-Stale.png":             encode(t, codec.CardPayload{Deezer: "3135556"}),
+	}
+	fakeScan(t, scans)
+	if err := Verify(opts); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+
+	// Swapped codes and an unreadable back.
+	scans["1985-Take On Me.png"], scans["1985-Money for Nothing.png"] = scans["1985-Money for Nothing.png"], scans["1985-Take On Me.png"]
+	delete(scans, "1999-Stale.png")
+	if err := Verify(opts); err == nil || err.Error() != "6 of 6 card backs failed to verify" {
+		t.Errorf("Verify = %v, want every back to fail", err)
+	}
+
+	if err := Verify(Options{InputFile: input, OutputDir: t.TempDir(), Require: policy.Default}); err == nil || !strings.Contains(err.Error(), "no png card backs") {
+		t.Errorf("Verify of an empty directory = %v", err)
+	}
+}
+
+func TestRunRejectsUnreadableBacks(t *testing.T) {
+	r := testRenderer(t)
+	r.verify = true
+	for _, path := range []*string{&r.card.TextFont, &r.card.ArtistIcon, &r.card.SongIcon} {
+		*path = filepath.Join("../..", *path)
+	}
+	r.card.DPI = 100
+	genres, err := config.Default().Taxonomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.genres = genres
+	r.thumbnailDir = t.TempDir()
+
+	songs := []models.GeneratedSong{
+		{Title: "Take On Me", Artists: []string{"a-ha"}, Year: 1985, Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L"},
+		{Title: "Money for Nothing", Artists: []string{"Dire Straits"}, Year: 1985, Spotify: "https://open.spotify.com/track/4iV5W9uYEdYUVa79Axb7Rh"},
+	}
+	for _, g := range songs {
+		f, err := os.Create(filepath.Join(r.thumbnailDir, cardSong(&g, policy.Policy{}).FileName()+".jpeg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := jpeg.Encode(f, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	// The second back scans as the first song's code.
+	good, bad := cardSong(&songs[0], policy.Policy{}), cardSong(&songs[1], policy.Policy{})
+	fakeScan(t, map[string][]byte{
+		backFileName(good, "png"): encode(t, codec.CardPayload{Spotify: good.Spotify}),
+		backFileName(bad, "png"):  encode(t, codec.CardPayload{Spotify: good.Spotify}),
+	})
+
+	dir := t.TempDir()
+	printed, err := run(r, songs, dir, filepath.Join(dir, "overrides.json"), policy.Policy{})
+	if err == nil || !strings.Contains(err.Error(), "1 card backs failed to verify") {
+		t.Errorf("run = %v, want a verify error", err)
+	}
+	if printed != nil {
+		t.Errorf("run printed %d cards despite the failure", len(printed))
+	}
+
+	for _, f := range []struct{ dir, good, bad string }{
+		{outDirStdFrontName, frontFileName(good, "png"), frontFileName(bad, "png")},
+		{outDirMiniFrontName, frontFileName(good, "png"), frontFileName(bad, "png")},
+		{outDirStdBackName, backFileName(good, "png"), backFileName(bad, "png")},
+		{outDirMiniBackName, backFileName(good, "png"), backFileName(bad, "png")},
+	} {
+		var names []string
+		entries, err := os.ReadDir(filepath.Join(dir, f.dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if want := []string{f.good}; !reflect.DeepEqual(names, want) {
+			t.Errorf("%s = %v, want %v", f.dir, names, want)
+		}
+		if _, err := os.Stat(filepath.Join(dir, outDirRejectedName, f.dir, f.bad)); err != nil {
+			t.Errorf("rejected card: %v", err)
+		}
+	}
+}
+
+// TestVerifyCardBackScans draws real backs and scans them with the decoder
+// verify uses, at the card size and at the smallest module allowed.
+func TestVerifyCardBackScans(t *testing.T) {
+	song := &models.Song{Title: "Take On Me", Year: 1985, Spotify: "2WfaOiMkCvy7F5fcp2zZ8L", Deezer: "664107", AppleMusic: "1440832700:1440832755"}
+	for _, minModule := range []bool{false, true} {
+		r := testRenderer(t)
+		q, err := r.createQRCode(song)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if minModule {
+			// Shrink the cards until their modules are MinModule wide.
+			module := math.Ceil(r.card.QR.MinModule * r.card.DPI)
+			side := (module*float64(q.size())+0.5)/r.card.DPI + 2*r.card.Margin
+			r.card.Standard = config.Size{Width: side, Height: side}
+			r.card.USMini = r.card.Standard
+		}
+		dir := t.TempDir()
+		if err := r.generateCardBack(song, q, dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.verifyCardBack(song, dir); err != nil {
+			t.Errorf("min module %v: verifyCardBack = %v", minModule, err)
+		}
+
+		// A card of the same name printed for another track.
+		other := *song
+		other.Spotify = "4iV5W9uYEdYUVa79Axb7Rh"
+		if err := r.verifyCardBack(&other, dir); err == nil || !strings.Contains(err.Error(), "Spotify mismatch") {
+			t.Errorf("min module %v: verifyCardBack of another track = %v", minModule, err)
+		}
+	}
+}
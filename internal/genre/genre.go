@@ -0,0 +1,253 @@
+// Package genre is the card genre taxonomy: the canonical genres, the
+// Spotify genres collect searches for each, the rules mapping an artist's
+// Spotify genres onto them, and how each is named and drawn.
+package genre
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Unknown is the genre of songs no rule maps. It is drawn with the
+// fallback theme.
+const Unknown = "default"
+
+// Genre is a canonical card genre.
+type Genre struct {
+	// Display is the name shown to people, e.g. "Hip-Hop".
+	Display string `yaml:"display,omitempty"`
+	// Search holds the Spotify genres collect searches with a genre:
+	// filter.
+	Search []string `yaml:"search,omitempty"`
+	// Match holds the terms mapping an artist to the genre: an artist
+	// genre containing one of them matches.
+	Match []string `yaml:"match,omitempty"`
+	// Priority decides between genres matching the same artist; the
+	// highest wins.
+	Priority int `yaml:"priority,omitempty"`
+	// Aliases are other names the genre is known by, such as names used
+	// by older summaries and overrides.
+	Aliases []string `yaml:"aliases,omitempty"`
+	Theme   *Theme   `yaml:"theme,omitempty"`
+}
+
+// Theme colours a card front: Dark fills the card and Light draws the
+// text, icons and artwork border.
+type Theme struct {
+	Light Color  `yaml:"light"`
+	Dark  Color  `yaml:"dark"`
+	Icon  string `yaml:"icon,omitempty"`
+}
+
+// Color is an opaque colour written as "#rrggbb".
+type Color color.RGBA
+
+func (c *Color) UnmarshalYAML(n *yaml.Node) error {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(n.Value, "#%02x%02x%02x", &r, &g, &b); err != nil || len(n.Value) != 7 {
+		return fmt.Errorf("line %d: invalid colour %q, want #rrggbb", n.Line, n.Value)
+	}
+	*c = Color{R: r, G: g, B: b, A: 255}
+	return nil
+}
+
+func (c Color) MarshalYAML() (any, error) {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), nil
+}
+
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+// Defaults returns the built-in genres. Jazz, blues and funk share a genre
+// drawn with the funk theme, which is what cards printed as "funk" used.
+func Defaults() map[string]Genre {
+	return map[string]Genre{
+		"pop": {
+			Display:  "Pop",
+			Search:   []string{"pop", "dance pop", "electropop", "synthpop", "r&b", "soul", "disco"},
+			Match:    []string{"pop", "dance", "electro", "synth", "r&b", "soul", "disco"},
+			Priority: 10,
+			Theme:    &Theme{Light: Color{255, 192, 203, 255}, Dark: Color{255, 105, 180, 255}, Icon: "assets/icons/popIcon.jpg"},
+		},
+		"rock": {
+			Display:  "Rock",
+			Search:   []string{"rock", "hard rock", "classic rock", "alternative rock", "punk", "metal", "indie"},
+			Match:    []string{"rock", "metal", "punk", "indie", "alternative", "grunge"},
+			Priority: 20,
+			Theme:    &Theme{Light: Color{173, 216, 230, 255}, Dark: Color{0, 0, 139, 255}, Icon: "assets/icons/rockIcon.jpg"},
+		},
+		"jazz": {
+			Display:  "Jazz & Funk",
+			Search:   []string{"jazz", "blues", "funk"},
+			Match:    []string{"jazz", "blues", "funk", "swing", "bebop"},
+			Priority: 30,
+			Aliases:  []string{"funk"},
+			Theme:    &Theme{Light: Color{192, 128, 192, 255}, Dark: Color{80, 0, 80, 255}, Icon: "assets/icons/funkIcon.jpg"},
+		},
+		"country": {
+			Display:  "Country",
+			Search:   []string{"country", "folk", "americana", "bluegrass"},
+			Match:    []string{"country", "folk", "americana", "bluegrass"},
+			Priority: 40,
+			Theme:    &Theme{Light: Color{255, 255, 153, 255}, Dark: Color{184, 134, 11, 255}, Icon: "assets/icons/countryIcon.jpg"},
+		},
+		"hip-hop": {
+			Display:  "Hip-Hop",
+			Search:   []string{"hip hop", "rap", "trap"},
+			Match:    []string{"hip hop", "rap", "trap", "drill", "grime"},
+			Priority: 50,
+			Theme:    &Theme{Light: Color{255, 160, 122, 255}, Dark: Color{139, 0, 0, 255}, Icon: "assets/icons/hiphopIcon.jpg"},
+		},
+	}
+}
+
+// Taxonomy maps names and Spotify artist genres to canonical genres.
+type Taxonomy struct {
+	genres   map[string]Genre
+	names    []string
+	aliases  map[string]string
+	rules    []rule
+	fallback Theme
+}
+
+// rule maps artist genres containing term to genre.
+type rule struct {
+	term     string
+	genre    string
+	priority int
+}
+
+// New checks genres and returns their taxonomy. Genres without a theme, and
+// Unknown, are drawn with fallback.
+func New(genres map[string]Genre, fallback Theme) (*Taxonomy, error) {
+	t := &Taxonomy{genres: genres, aliases: make(map[string]string), fallback: fallback}
+	for name := range genres {
+		t.names = append(t.names, name)
+	}
+	sort.Strings(t.names)
+
+	var errs []error
+	searched := false
+	for _, name := range t.names {
+		g := genres[name]
+		if name == "" || name != strings.ToLower(strings.TrimSpace(name)) {
+			errs = append(errs, fmt.Errorf("genres: name %q must be lower case", name))
+		}
+		if name == Unknown {
+			errs = append(errs, fmt.Errorf("genres: %q is reserved for songs no rule maps", Unknown))
+		}
+		for _, term := range append(append([]string(nil), g.Search...), g.Match...) {
+			if strings.TrimSpace(term) == "" {
+				errs = append(errs, fmt.Errorf("genres.%s: empty term", name))
+			}
+		}
+		for _, alias := range g.Aliases {
+			alias = strings.ToLower(alias)
+			if _, ok := genres[alias]; ok {
+				errs = append(errs, fmt.Errorf("genres.%s: alias %q is itself a genre", name, alias))
+			} else if other, ok := t.aliases[alias]; ok {
+				errs = append(errs, fmt.Errorf("genres.%s: alias %q is also an alias of %s", name, alias, other))
+			}
+			t.aliases[alias] = name
+		}
+		for _, term := range g.Match {
+			t.rules = append(t.rules, rule{term: strings.ToLower(term), genre: name, priority: g.Priority})
+		}
+		searched = searched || len(g.Search) > 0
+	}
+	if !searched {
+		errs = append(errs, errors.New("genres: no genre has search terms, so collect would find nothing"))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Highest priority first; the stable sort keeps name and term order
+	// within a priority.
+	sort.SliceStable(t.rules, func(i, j int) bool { return t.rules[i].priority > t.rules[j].priority })
+	return t, nil
+}
+
+// Names returns the canonical genre names in sorted order.
+func (t *Taxonomy) Names() []string {
+	return append([]string(nil), t.names...)
+}
+
+// Search returns the Spotify genres collect searches, by genre. Genres
+// without search terms are left out.
+func (t *Taxonomy) Search() map[string][]string {
+	search := make(map[string][]string)
+	for name, g := range t.genres {
+		if len(g.Search) > 0 {
+			search[name] = g.Search
+		}
+	}
+	return search
+}
+
+// Canonical returns the genre a name or alias refers to, ignoring case,
+// or Unknown.
+func (t *Taxonomy) Canonical(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := t.genres[name]; ok {
+		return name
+	}
+	if canonical, ok := t.aliases[name]; ok {
+		return canonical
+	}
+	return Unknown
+}
+
+// Classify maps an artist's Spotify genres to a canonical genre. Of the
+// genres with a term contained in any artist genre, the highest priority
+// wins, then the one matching the earliest artist genre. It returns false
+// if nothing matches.
+func (t *Taxonomy) Classify(artistGenres []string) (string, bool) {
+	for _, r := range t.rules {
+		for _, ag := range artistGenres {
+			if strings.Contains(strings.ToLower(ag), r.term) {
+				return t.best(artistGenres, r.priority), true
+			}
+		}
+	}
+	return Unknown, false
+}
+
+// best returns the genre of the earliest artist genre matched by a rule of
+// the given priority.
+func (t *Taxonomy) best(artistGenres []string, priority int) string {
+	for _, ag := range artistGenres {
+		ag = strings.ToLower(ag)
+		for _, r := range t.rules {
+			if r.priority == priority && strings.Contains(ag, r.term) {
+				return r.genre
+			}
+		}
+	}
+	return Unknown
+}
+
+// Display returns the human readable name of a genre.
+func (t *Taxonomy) Display(name string) string {
+	if g, ok := t.genres[t.Canonical(name)]; ok && g.Display != "" {
+		return g.Display
+	}
+	if canonical := t.Canonical(name); canonical != Unknown {
+		return canonical
+	}
+	return "Other"
+}
+
+// Theme returns how cards of a genre are drawn.
+func (t *Taxonomy) Theme(name string) Theme {
+	if g, ok := t.genres[t.Canonical(name)]; ok && g.Theme != nil {
+		return *g.Theme
+	}
+	return t.fallback
+}
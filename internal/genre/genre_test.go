@@ -0,0 +1,149 @@
+package genre
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func defaults(t *testing.T) *Taxonomy {
+	t.Helper()
+	tax, err := New(Defaults(), Theme{Dark: Color{1, 2, 3, 255}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tax
+}
+
+func TestClassify(t *testing.T) {
+	tax := defaults(t)
+	tests := []struct {
+		artistGenres []string
+		want         string
+		ok           bool
+	}{
+		{[]string{"dance pop"}, "pop", true},
+		// hip-hop outranks pop whatever the order
+		{[]string{"pop", "pop rap"}, "hip-hop", true},
+		{[]string{"Atlanta Hip Hop"}, "hip-hop", true},
+		// rock and pop rules on a single artist: rock has the higher priority
+		{[]string{"pop rock"}, "rock", true},
+		{[]string{"uk drill"}, "hip-hop", true},
+		{[]string{"bebop", "cool jazz"}, "jazz", true},
+		{[]string{"polka"}, Unknown, false},
+		{nil, Unknown, false},
+	}
+	for _, tt := range tests {
+		got, ok := tax.Classify(tt.artistGenres)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Classify(%q) = %q, %v, want %q, %v", tt.artistGenres, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyEarliestWithinPriority(t *testing.T) {
+	tax, err := New(map[string]Genre{
+		"a": {Search: []string{"a"}, Match: []string{"alpha"}},
+		"b": {Match: []string{"beta"}},
+	}, Theme{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		artistGenres []string
+		want         string
+	}{
+		{[]string{"beta", "alpha"}, "b"},
+		{[]string{"alpha", "beta"}, "a"},
+	} {
+		if got, _ := tax.Classify(tt.artistGenres); got != tt.want {
+			t.Errorf("Classify(%q) = %q, want %q", tt.artistGenres, got, tt.want)
+		}
+	}
+}
+
+func TestNamesAndThemes(t *testing.T) {
+	tax := defaults(t)
+	if got, want := tax.Names(), []string{"country", "hip-hop", "jazz", "pop", "rock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, want %v", got, want)
+	}
+	for name, want := range map[string]string{"Jazz": "jazz", "funk": "jazz", " hip-hop ": "hip-hop", "polka": Unknown} {
+		if got := tax.Canonical(name); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := tax.Display("funk"); got != "Jazz & Funk" {
+		t.Errorf("Display(funk) = %q", got)
+	}
+	if got := tax.Display(Unknown); got != "Other" {
+		t.Errorf("Display(%q) = %q", Unknown, got)
+	}
+	if got := tax.Theme("funk").Icon; got != "assets/icons/funkIcon.jpg" {
+		t.Errorf("Theme(funk).Icon = %q", got)
+	}
+	if got := tax.Theme(Unknown); got != (Theme{Dark: Color{1, 2, 3, 255}}) {
+		t.Errorf("Theme(%q) = %+v, want the fallback", Unknown, got)
+	}
+	if got := tax.Search(); len(got) != 5 || got["jazz"][0] != "jazz" {
+		t.Errorf("Search = %v", got)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(map[string]Genre{
+		"Rock":    {Search: []string{"rock"}},
+		"default": {Match: []string{"x"}},
+		"pop":     {Match: []string{" "}, Aliases: []string{"rock-ish", "jazz"}},
+		"jazz":    {Aliases: []string{"rock-ish"}},
+	}, Theme{})
+	if err == nil {
+		t.Fatal("New succeeded")
+	}
+	for _, want := range []string{
+		`name "Rock" must be lower case`,
+		`"default" is reserved`,
+		"genres.pop: empty term",
+		`genres.pop: alias "jazz" is itself a genre`,
+		`genres.pop: alias "rock-ish" is also an alias of jazz`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	if _, err := New(map[string]Genre{"funk": {Match: []string{"funk"}}}, Theme{}); err == nil || !strings.Contains(err.Error(), "no genre has search terms") {
+		t.Errorf("New without search terms = %v", err)
+	}
+}
+
+func TestUnmapped(t *testing.T) {
+	var buf bytes.Buffer
+	var u Unmapped
+	u.Write(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("empty Write printed %q", buf.String())
+	}
+
+	for i, song := range []string{"A", "B", "C", "D"} {
+		u.Add(song, []string{"polka"})
+		if i == 0 {
+			u.Add("E", nil)
+		}
+	}
+	u.Write(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		`5 songs got genre "default"`,
+		"polka",
+		"A, B, C, and 1 more",
+		noGenres,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write = %q, want %q", out, want)
+		}
+	}
+	if strings.Index(out, "polka") > strings.Index(out, noGenres) {
+		t.Errorf("Write = %q, want the most common genre first", out)
+	}
+}
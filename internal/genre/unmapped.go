@@ -0,0 +1,73 @@
+package genre
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Unmapped collects the Spotify artist genres of songs that Classify could
+// not map, to show which rules a taxonomy is missing. It is safe for
+// concurrent use.
+type Unmapped struct {
+	mu sync.Mutex
+	// songs maps a raw artist genre to the songs it was seen on.
+	songs map[string][]string
+	total int
+}
+
+// noGenres stands for artists Spotify lists without any genre.
+const noGenres = "(no genres)"
+
+// Add records a song whose artist genres did not map.
+func (u *Unmapped) Add(song string, artistGenres []string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.songs == nil {
+		u.songs = make(map[string][]string)
+	}
+	u.total++
+	if len(artistGenres) == 0 {
+		artistGenres = []string{noGenres}
+	}
+	for _, g := range artistGenres {
+		u.songs[g] = append(u.songs[g], song)
+	}
+}
+
+// Write prints the unmapped artist genres, most common first, with a few
+// of the songs they came from. It prints nothing if every song mapped.
+func (u *Unmapped) Write(w io.Writer) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.total == 0 {
+		return
+	}
+
+	genres := make([]string, 0, len(u.songs))
+	for g := range u.songs {
+		genres = append(genres, g)
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		if a, b := len(u.songs[genres[i]]), len(u.songs[genres[j]]); a != b {
+			return a > b
+		}
+		return genres[i] < genres[j]
+	})
+
+	fmt.Fprintf(w, "Unmapped genres: %d songs got genre %q; their artist genres:\n", u.total, Unknown)
+	for _, g := range genres {
+		songs := u.songs[g]
+		examples := songs
+		if len(examples) > 3 {
+			examples = examples[:3]
+		}
+		more := ""
+		if len(songs) > len(examples) {
+			more = fmt.Sprintf(", and %d more", len(songs)-len(examples))
+		}
+		fmt.Fprintf(w, "  %-30s %3d  %s%s\n", g, len(songs), strings.Join(examples, ", "), more)
+	}
+}
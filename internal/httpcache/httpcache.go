@@ -0,0 +1,229 @@
+// Package httpcache is an on-disk HTTP response cache. Responses are stored
+// under the SHA-256 of the request method and URL, so reruns of a lookup
+// replay exactly what upstream services returned the first time.
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrMiss is returned in offline mode when a request has no cached response.
+var ErrMiss = errors.New("not in cache")
+
+// storedAtHeader records when a response was written to the cache.
+const storedAtHeader = "X-Httpcache-Stored-At"
+
+// DefaultTTLs are the cache lifetimes for the upstream services lookup
+// talks to. Track metadata and cross-platform links rarely change, search
+// results and pages drift faster. A TTL of zero disables caching.
+var DefaultTTLs = map[string]time.Duration{
+	"api.spotify.com":      30 * 24 * time.Hour,
+	"api.song.link":        30 * 24 * time.Hour,
+	"itunes.apple.com":     7 * 24 * time.Hour,
+	"music.apple.com":      7 * 24 * time.Hour,
+	"www.amazon.com":       7 * 24 * time.Hour,
+	"music.amazon.com":     7 * 24 * time.Hour,
+	"www.youtube.com":      7 * 24 * time.Hour,
+	"api.deezer.com":       7 * 24 * time.Hour,
+	"tidal.com":            7 * 24 * time.Hour,
+	"musicbrainz.org":      30 * 24 * time.Hour,
+	"i.scdn.co":            0, // thumbnails are kept on disk by lookup already
+	"accounts.spotify.com": 0,
+}
+
+// DefaultTTL applies to hosts without an entry in the TTL map.
+const DefaultTTL = 24 * time.Hour
+
+// now is replaced by tests.
+var now = time.Now
+
+// Transport is an http.RoundTripper that serves GET requests from disk when
+// a fresh copy is cached and stores successful responses from Base.
+type Transport struct {
+	// Dir is the cache directory.
+	Dir string
+	// Base performs requests that miss the cache. Defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+	// TTLs maps a host to how long its responses stay fresh. A host also
+	// matches its subdomains. Hosts not listed use DefaultTTL.
+	TTLs map[string]time.Duration
+	// Offline serves only from cache, ignoring TTLs, and fails with ErrMiss
+	// instead of touching the network.
+	Offline bool
+
+	hits, fetched atomic.Int64
+}
+
+// New returns a Transport caching under dir with the default TTLs.
+func New(dir string, base http.RoundTripper) *Transport {
+	ttls := make(map[string]time.Duration, len(DefaultTTLs))
+	for host, ttl := range DefaultTTLs {
+		ttls[host] = ttl
+	}
+	return &Transport{Dir: dir, Base: base, TTLs: ttls}
+}
+
+// Stats returns the number of responses served from the cache and of
+// requests that went to the network.
+func (t *Transport) Stats() (hits, fetched int64) {
+	return t.hits.Load(), t.fetched.Load()
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ttl := t.ttl(req.URL.Hostname())
+	cacheable := req.Method == http.MethodGet && (ttl > 0 || t.Offline)
+	if !cacheable {
+		if t.Offline {
+			return nil, fmt.Errorf("%w: offline, refusing %s %s", ErrMiss, req.Method, req.URL)
+		}
+		return t.fetch(req)
+	}
+
+	path := t.path(req)
+	if resp, storedAt, err := readResponse(path, req); err == nil {
+		if t.Offline || now().Sub(storedAt) < ttl {
+			t.hits.Add(1)
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+
+	if t.Offline {
+		return nil, fmt.Errorf("%w: offline, no cached response for %s %s", ErrMiss, req.Method, req.URL)
+	}
+
+	resp, err := t.fetch(req)
+	if err != nil {
+		return nil, err
+	}
+	if !storable(resp.StatusCode) {
+		return resp, nil
+	}
+
+	resp.Header.Set(storedAtHeader, now().UTC().Format(time.RFC3339))
+	dump, err := httputil.DumpResponse(resp, true)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(path, dump); err != nil {
+		return nil, fmt.Errorf("httpcache: %w", err)
+	}
+
+	resp, _, err = parseResponse(dump, req)
+	return resp, err
+}
+
+func (t *Transport) fetch(req *http.Request) (*http.Response, error) {
+	t.fetched.Add(1)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// ttl returns the TTL for host, falling back to its parent domains.
+func (t *Transport) ttl(host string) time.Duration {
+	for h := host; h != ""; {
+		if ttl, ok := t.TTLs[h]; ok {
+			return ttl
+		}
+		_, parent, found := strings.Cut(h, ".")
+		if !found {
+			break
+		}
+		h = parent
+	}
+	return DefaultTTL
+}
+
+func (t *Transport) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(t.Dir, key[:2], key)
+}
+
+// storable reports whether a response is worth replaying. Not-found answers
+// are kept because validators rely on them; throttling and server errors are
+// not.
+func storable(status int) bool {
+	return status == http.StatusOK || status == http.StatusNotFound || status == http.StatusGone
+}
+
+func readResponse(path string, req *http.Request) (*http.Response, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return parseResponse(data, req)
+}
+
+func parseResponse(data []byte, req *http.Request) (*http.Response, time.Time, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	storedAt, err := time.Parse(time.RFC3339, resp.Header.Get(storedAtHeader))
+	if err != nil {
+		resp.Body.Close()
+		return nil, time.Time{}, fmt.Errorf("httpcache: bad %s header: %w", storedAtHeader, err)
+	}
+	return resp, storedAt, nil
+}
+
+// writeFile writes via a temporary file so a crash never leaves a partial
+// entry behind.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ParseTTLs parses a comma separated list of host=duration overrides, e.g.
+// "api.song.link=720h,www.youtube.com=0", into ttls.
+func ParseTTLs(list string, ttls map[string]time.Duration) error {
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid cache ttl %q, want host=duration", entry)
+		}
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid cache ttl for %s: %w", host, err)
+		}
+		ttls[strings.TrimSpace(host)] = ttl
+	}
+	return nil
+}
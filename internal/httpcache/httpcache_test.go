@@ -0,0 +1,248 @@
+package httpcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// upstream answers with the request number, and the status given by
+// ?status=.
+func upstream(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if status, err := strconv.Atoi(r.URL.Query().Get("status")); err == nil {
+			w.WriteHeader(status)
+		}
+		fmt.Fprintf(w, "%s %s #%d", r.Method, r.URL.Path, n)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+// fakeNow stops the clock at t0 and returns a function moving it on.
+func fakeNow(t *testing.T) func(time.Duration) {
+	t.Helper()
+	at := t0
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) { at = at.Add(d) }
+}
+
+func newTransport(t *testing.T, srv *httptest.Server) *Transport {
+	t.Helper()
+	tr := New(t.TempDir(), srv.Client().Transport)
+	tr.TTLs["127.0.0.1"] = time.Hour
+	return tr
+}
+
+// get returns the body of a request through tr, or the error.
+func get(t *testing.T, tr *Transport, method, url string) (string, int, error) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), resp.StatusCode, err
+}
+
+func TestTransport(t *testing.T) {
+	advance := fakeNow(t)
+	srv, requests := upstream(t)
+	tr := newTransport(t, srv)
+
+	for i := 0; i < 3; i++ {
+		body, status, err := get(t, tr, http.MethodGet, srv.URL+"/track")
+		if err != nil || status != http.StatusOK || body != "GET /track #1" {
+			t.Fatalf("GET %d = %q, %d, %v; want the first response replayed", i, body, status, err)
+		}
+	}
+	// Only GETs are cached.
+	for i := 2; i <= 3; i++ {
+		if body, _, _ := get(t, tr, http.MethodPost, srv.URL+"/track"); body != fmt.Sprintf("POST /track #%d", i) {
+			t.Errorf("POST = %q, want a fresh response", body)
+		}
+	}
+	if hits, fetched := tr.Stats(); hits != 2 || fetched != 3 {
+		t.Errorf("Stats = %d hits, %d fetched, want 2, 3", hits, fetched)
+	}
+
+	// Entries expire after the host's TTL.
+	advance(59 * time.Minute)
+	if body, _, _ := get(t, tr, http.MethodGet, srv.URL+"/track"); body != "GET /track #1" {
+		t.Errorf("GET before expiry = %q", body)
+	}
+	advance(2 * time.Minute)
+	if body, _, _ := get(t, tr, http.MethodGet, srv.URL+"/track"); body != "GET /track #4" {
+		t.Errorf("GET after expiry = %q, want a fresh response", body)
+	}
+	if body, _, _ := get(t, tr, http.MethodGet, srv.URL+"/track"); body != "GET /track #4" {
+		t.Errorf("GET after refresh = %q, want the refreshed response", body)
+	}
+
+	// A TTL of zero disables caching for the host.
+	tr.TTLs["127.0.0.1"] = 0
+	get(t, tr, http.MethodGet, srv.URL+"/track")
+	if body, _, _ := get(t, tr, http.MethodGet, srv.URL+"/track"); body != "GET /track #6" {
+		t.Errorf("GET with a zero TTL = %q, want a fresh response", body)
+	}
+	if n := requests.Load(); n != 6 {
+		t.Errorf("upstream saw %d requests, want 6", n)
+	}
+}
+
+func TestStorable(t *testing.T) {
+	srv, _ := upstream(t)
+	tr := newTransport(t, srv)
+	for status, stored := range map[int]bool{
+		http.StatusOK:                  true,
+		http.StatusNotFound:            true,
+		http.StatusGone:                true,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		url := fmt.Sprintf("%s/status?status=%d", srv.URL, status)
+		first, code, err := get(t, tr, http.MethodGet, url)
+		if err != nil || code != status {
+			t.Fatalf("GET %d = %d, %v", status, code, err)
+		}
+		second, code, _ := get(t, tr, http.MethodGet, url)
+		if code != status || (second == first) != stored {
+			t.Errorf("status %d: second response %q after %q, want stored = %v", status, second, first, stored)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	advance := fakeNow(t)
+	srv, requests := upstream(t)
+	tr := newTransport(t, srv)
+	if _, _, err := get(t, tr, http.MethodGet, srv.URL+"/cached"); err != nil {
+		t.Fatal(err)
+	}
+
+	tr.Offline = true
+	// Offline replays expired entries rather than failing.
+	advance(48 * time.Hour)
+	if body, _, err := get(t, tr, http.MethodGet, srv.URL+"/cached"); err != nil || body != "GET /cached #1" {
+		t.Errorf("offline GET of an expired entry = %q, %v", body, err)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if _, _, err := get(t, tr, method, srv.URL+"/missing"); !errors.Is(err, ErrMiss) {
+			t.Errorf("offline %s of an uncached URL = %v, want ErrMiss", method, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("upstream saw %d requests, want 1", n)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	tr := &Transport{TTLs: map[string]time.Duration{
+		"spotify.com":     time.Hour,
+		"api.spotify.com": 0,
+	}}
+	for host, want := range map[string]time.Duration{
+		"spotify.com":          time.Hour,
+		"open.spotify.com":     time.Hour,
+		"api.spotify.com":      0,
+		"eu.api.spotify.com":   0,
+		"example.com":          DefaultTTL,
+		"localhost":            DefaultTTL,
+		"notspotify.com":       DefaultTTL,
+		"spotify.com.evil.net": DefaultTTL,
+	} {
+		if got := tr.ttl(host); got != want {
+			t.Errorf("ttl(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestCorruptEntry(t *testing.T) {
+	srv, _ := upstream(t)
+	tr := newTransport(t, srv)
+	url := srv.URL + "/track"
+	get(t, tr, http.MethodGet, url)
+
+	// A damaged entry is refetched and replaced.
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if err := os.WriteFile(tr.path(req), []byte("HTTP/1.1 200 OK\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if body, _, _ := get(t, tr, http.MethodGet, url); body != "GET /track #2" {
+		t.Errorf("GET of a damaged entry = %q, want a fresh response", body)
+	}
+	if body, _, _ := get(t, tr, http.MethodGet, url); body != "GET /track #2" {
+		t.Errorf("GET after replacing a damaged entry = %q", body)
+	}
+
+	// Writes go through a temporary file that is renamed into place.
+	var files []string
+	filepath.WalkDir(tr.Dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, filepath.Base(path))
+		}
+		return nil
+	})
+	if len(files) != 1 || strings.HasPrefix(files[0], ".tmp-") {
+		t.Errorf("cache holds %v, want one entry and no temporary files", files)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ab", "entry")
+	for _, data := range []string{"first", "second"} {
+		if err := writeFile(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("entry = %q, %v, want %q", got, err, data)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the entry", len(entries))
+	}
+
+	if err := writeFile(filepath.Join(path, "child"), []byte("x")); err == nil {
+		t.Error("writeFile under a file succeeded")
+	}
+}
+
+func TestParseTTLs(t *testing.T) {
+	ttls := map[string]time.Duration{"api.song.link": time.Hour, "tidal.com": time.Hour}
+	if err := ParseTTLs(" api.song.link=720h, www.youtube.com=0,,", ttls); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Duration{"api.song.link": 720 * time.Hour, "www.youtube.com": 0, "tidal.com": time.Hour}
+	if !reflect.DeepEqual(ttls, want) {
+		t.Errorf("ttls = %v, want %v", ttls, want)
+	}
+	for _, list := range []string{"api.song.link", "api.song.link=forever", "api.song.link=720"} {
+		if err := ParseTTLs(list, map[string]time.Duration{}); err == nil {
+			t.Errorf("ParseTTLs(%q) succeeded", list)
+		}
+	}
+}
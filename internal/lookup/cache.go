@@ -0,0 +1,69 @@
+package lookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"temporalize/internal/httpcache"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// responseCache wraps every HTTP client lookup uses in an on-disk cache
+// sharing one directory and TTL table.
+type responseCache struct {
+	dir        string
+	ttls       map[string]time.Duration
+	offline    bool
+	transports []*httpcache.Transport
+}
+
+func newResponseCache(dir, ttlOverrides string, offline bool) (*responseCache, error) {
+	ttls := make(map[string]time.Duration, len(httpcache.DefaultTTLs))
+	for host, ttl := range httpcache.DefaultTTLs {
+		ttls[host] = ttl
+	}
+	if err := httpcache.ParseTTLs(ttlOverrides, ttls); err != nil {
+		return nil, err
+	}
+	return &responseCache{dir: dir, ttls: ttls, offline: offline}, nil
+}
+
+// wrap returns base behind the cache, or base itself if caching is disabled.
+func (c *responseCache) wrap(base http.RoundTripper) http.RoundTripper {
+	if c.dir == "" {
+		return base
+	}
+	t := httpcache.New(c.dir, base)
+	t.TTLs = c.ttls
+	t.Offline = c.offline
+	c.transports = append(c.transports, t)
+	return t
+}
+
+// report prints how many responses the cache served and how many requests
+// went to the network.
+func (c *responseCache) report(w io.Writer) {
+	if c.dir == "" {
+		return
+	}
+	var hits, fetched int64
+	for _, t := range c.transports {
+		h, f := t.Stats()
+		hits, fetched = hits+h, fetched+f
+	}
+	fmt.Fprintf(w, "HTTP cache: %d responses served from %s, %d fetched\n", hits, c.dir, fetched)
+}
+
+// checkRetry stops retryablehttp from backing off on offline cache misses,
+// which will never succeed.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if errors.Is(err, httpcache.ErrMiss) {
+		return false, err
+	}
+	return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+}
@@ -0,0 +1,96 @@
+package lookup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var cleanPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\s-\s.*Remaster.*`),
+	regexp.MustCompile(`(?i)\s\(.*Remaster.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Mix.*`),
+	regexp.MustCompile(`(?i)\s\(.*Mix.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Radio.*`),
+	regexp.MustCompile(`(?i)\s\(.*Radio.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Live.*`),
+	regexp.MustCompile(`(?i)\s\(.*Live.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Mono.*`),
+	regexp.MustCompile(`(?i)\s\(.*Mono.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Stereo.*`),
+	regexp.MustCompile(`(?i)\s\(.*Stereo.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Feat.*`),
+	regexp.MustCompile(`(?i)\s\(.*Feat.*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Ft\..*`),
+	regexp.MustCompile(`(?i)\s\(.*Ft\..*\)`),
+	regexp.MustCompile(`(?i)\s-\s.*Version\..*`),
+	regexp.MustCompile(`(?i)\s\(.*Version\..*\)`),
+}
+
+func cleanTitle(title string) string {
+	newTitle := title
+	for _, re := range cleanPatterns {
+		newTitle = re.ReplaceAllString(newTitle, "")
+	}
+	return strings.TrimSpace(newTitle)
+}
+
+// fixLinks validates each platform's ID and searches for a replacement when
+// it is missing or does not match the song, recording each platform's
+// status. A link that could not be checked because the platform was
+// unreachable is kept as unverified; one that failed is cleared. It reports
+// whether no platform failed.
+func fixLinks(client *retryablehttp.Client, song *models.Song, platforms []platform.Platform) bool {
+	isValid := true
+	for _, p := range platforms {
+		status := fixLink(client, song, p)
+		song.SetStatus(p.Key(), status)
+		if status.Status == models.StatusFailed {
+			isValid = false
+			fmt.Printf("Failed to fix %s link for %s: %s\n", p.Name(), song.Title, status.Reason)
+		}
+	}
+	return isValid
+}
+
+func fixLink(client *retryablehttp.Client, song *models.Song, p platform.Platform) models.PlatformStatus {
+	reason := "no link from Odesli"
+	id := song.Link(p.Key())
+	var validateErr error
+	if id != "" {
+		m, err := p.Validate(client, id, song)
+		if err == nil {
+			return models.PlatformStatus{Status: models.StatusOK, Method: linkMethod(m, models.MethodOdesli), Score: m.Score}
+		}
+		validateErr = err
+		reason = fmt.Sprintf("rejected %s: %v", id, err)
+	}
+
+	newID, m, err := p.Search(client, song)
+	if err == nil {
+		song.SetLink(p.Key(), newID)
+		return models.PlatformStatus{Status: models.StatusFixed, Method: linkMethod(m, models.MethodSearch), Score: m.Score, Reason: reason}
+	}
+
+	if id != "" && !platform.Mismatch(validateErr) {
+		return models.PlatformStatus{Status: models.StatusUnverified, Method: models.MethodOdesli, Reason: fmt.Sprintf("could not check: %v", validateErr)}
+	}
+	song.SetLink(p.Key(), "")
+	return models.PlatformStatus{Status: models.StatusFailed, Reason: fmt.Sprintf("%s; search: %v", reason, err)}
+}
+
+// linkMethod names where a link came from, given how it matched.
+func linkMethod(m models.Match, found string) string {
+	switch m.Method {
+	case platform.MatchSource:
+		return models.MethodSource
+	case platform.MatchISRC:
+		return models.MethodISRC
+	}
+	return found
+}
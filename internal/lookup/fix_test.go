@@ -0,0 +1,60 @@
+package lookup
+
+import (
+	"testing"
+
+	"temporalize/internal/fakes"
+	"temporalize/internal/match"
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+func TestFixLinks(t *testing.T) {
+	srv := fakes.New()
+	defer srv.Close()
+	platforms, err := platform.Select("", platform.Config{Upstream: srv.Endpoints(), MatchThreshold: match.DefaultThreshold})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+
+	links, err := fetchLinks(client, srv.Endpoints().Odesli, "3fH4KjXFYMmljxrcGrbPj9", platforms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	song := &models.Song{
+		Title:   cleanTitle("Don't You (Forget About Me) - Remastered 2003"),
+		Artists: []string{"Simple Minds"},
+		ISRC:    "GBAAA8500123",
+	}
+	for key, id := range links {
+		song.SetLink(key, id)
+	}
+
+	if fixLinks(client, song, platforms) {
+		t.Error("fixLinks reported every platform valid, want Tidal failed")
+	}
+
+	want := map[string]struct{ status, method, id string }{
+		models.PlatformSpotify:      {models.StatusOK, models.MethodSource, "3fH4KjXFYMmljxrcGrbPj9"},
+		models.PlatformAppleMusic:   {models.StatusFixed, models.MethodISRC, "1440832700:1440832755"},
+		models.PlatformAmazonMusic:  {models.StatusOK, models.MethodOdesli, "B001NZMF0Q:B001NZHRXS"},
+		models.PlatformYoutubeMusic: {models.StatusFixed, models.MethodSearch, "CdqoNKCCt7A"},
+		models.PlatformDeezer:       {models.StatusOK, models.MethodISRC, "3135556"},
+		models.PlatformTidal:        {models.StatusFailed, "", ""},
+	}
+	for key, w := range want {
+		got := song.Platforms[key]
+		if got.Status != w.status || got.Method != w.method || song.Link(key) != w.id {
+			t.Errorf("%s = %+v with link %q, want %s/%s with link %q", key, got, song.Link(key), w.status, w.method, w.id)
+		}
+	}
+	if reason := song.Platforms[models.PlatformYoutubeMusic].Reason; reason == "" {
+		t.Error("fixed YouTube link has no reason")
+	}
+}
@@ -0,0 +1,59 @@
+package lookup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"temporalize/internal/platform"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const unitedStatesCountryKey = "US"
+
+type odesliResponse struct {
+	LinksByPlatform map[string]struct {
+		URL string `json:"url"`
+	} `json:"linksByPlatform"`
+	Error string `json:"error"`
+}
+
+// fetchLinks asks Odesli for the song's links and returns the compact ID
+// for each of the given platforms, keyed by platform key.
+func fetchLinks(client *retryablehttp.Client, odesliURL, spotifyID string, platforms []platform.Platform) (map[string]string, error) {
+	spotifyURI := "spotify:track:" + spotifyID
+	apiURL := fmt.Sprintf("%s/v1-alpha.1/links?url=%s&userCountry=%s", odesliURL, spotifyURI, unitedStatesCountryKey)
+
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("odesli status: %s", http.StatusText(resp.StatusCode))
+	}
+
+	var result odesliResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != "" {
+		return nil, fmt.Errorf("odesli error: %s", result.Error)
+	}
+
+	links := make(map[string]string)
+	for _, p := range platforms {
+		link, ok := result.LinksByPlatform[p.Key()]
+		if !ok {
+			continue
+		}
+		if id := p.ParseID(link.URL); id != "" {
+			links[p.Key()] = id
+		}
+	}
+
+	return links, nil
+}
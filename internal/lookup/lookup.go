@@ -0,0 +1,376 @@
+// Package lookup resolves each collected song's metadata and its links on
+// every platform, validating and fixing them, and writes the summary that
+// generate prints cards from.
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/config"
+	"temporalize/internal/endpoints"
+	"temporalize/internal/genre"
+	"temporalize/internal/models"
+	"temporalize/internal/musicbrainz"
+	"temporalize/internal/overrides"
+	"temporalize/internal/platform"
+	"temporalize/internal/ratelimit"
+	"temporalize/internal/spotifyclient"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// spotifyPlatform parses collected song URLs; Spotify is always looked up
+// since it is the source of every song.
+var spotifyPlatform, _ = platform.Lookup(models.PlatformSpotify)
+
+// Options configure a lookup run. They hold flag values as given; Run
+// parses and checks them.
+type Options struct {
+	InputFile   string
+	SummaryFile string
+	StartYear   int
+	EndYear     int
+	// Platforms is a comma separated list of platform keys, empty for all.
+	Platforms string
+	// CacheDir holds cached HTTP responses; empty disables the cache.
+	CacheDir string
+	// CacheTTL overrides per-host cache lifetimes, see httpcache.ParseTTLs.
+	CacheTTL      string
+	Offline       bool
+	Resume        bool
+	OverridesFile string
+	Concurrency   int
+	// MusicBrainz resolves original release years, from MusicBrainzURL or
+	// else Upstream.
+	MusicBrainz    bool
+	MusicBrainzURL string
+	YearTolerance  int
+	MatchThreshold float64
+	Upstream       endpoints.Endpoints
+	// ReportFile is written with an HTML review report when set.
+	ReportFile string
+	// RateLimits overrides per-host limits, see ratelimit.ParseLimits.
+	RateLimits string
+	// Genres maps artist genres to card genres.
+	Genres       *genre.Taxonomy
+	ThumbnailDir string
+	Credentials  spotifyclient.Credentials
+	// Catalog, when set, is the catalogue Deck is read from and its
+	// songs written to instead of InputFile and SummaryFile.
+	Catalog string
+	Deck    string
+}
+
+// NewOptions returns the options cfg sets.
+func NewOptions(cfg config.Config) (Options, error) {
+	genres, err := cfg.Taxonomy()
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		InputFile:      cfg.Paths.Collect,
+		SummaryFile:    cfg.Paths.Lookup,
+		StartYear:      cfg.Years.Start,
+		EndYear:        cfg.Years.End,
+		CacheDir:       cfg.Paths.Cache,
+		OverridesFile:  cfg.Paths.Overrides,
+		Concurrency:    cfg.Lookup.Concurrency,
+		YearTolerance:  cfg.Lookup.YearTolerance,
+		MatchThreshold: cfg.Lookup.MatchThreshold,
+		Upstream:       endpoints.Default,
+		Genres:         genres,
+		ThumbnailDir:   cfg.Paths.Thumbnails,
+		Credentials:    spotifyclient.FromEnv(),
+		Catalog:        cfg.Paths.Catalog,
+		Deck:           cfg.Deck,
+	}, nil
+}
+
+type runOptions struct {
+	inputFile   string
+	summaryFile string
+	startYear   int
+	endYear     int
+	platforms   []platform.Platform
+	cache       *responseCache
+	resume      bool
+	overrides   overrides.Set
+	concurrency int
+	limiter     *ratelimit.Limiter
+	upstream    endpoints.Endpoints
+	credentials spotifyclient.Credentials
+	genres      *genre.Taxonomy
+	// thumbnailDir holds downloaded album art, named after each song.
+	thumbnailDir string
+	// musicbrainzURL enables original year resolution when set.
+	musicbrainzURL string
+	yearTolerance  int
+	// reportFile is written with an HTML review report when set.
+	reportFile string
+	// catalog replaces the input and summary files when set.
+	catalog *catalog.Catalog
+	deck    string
+}
+
+// Run looks up every collected song between the start and end years and
+// writes the summary, and the report if asked for.
+func Run(o Options) error {
+	if o.Offline && o.CacheDir == "" {
+		return fmt.Errorf("offline lookups need a cache directory")
+	}
+	cache, err := newResponseCache(o.CacheDir, o.CacheTTL, o.Offline)
+	if err != nil {
+		return err
+	}
+
+	if o.MatchThreshold < 0 || o.MatchThreshold > 1 {
+		return fmt.Errorf("match threshold must be between 0 and 1")
+	}
+	platforms, err := platform.Select(o.Platforms, platform.Config{Upstream: o.Upstream, MatchThreshold: o.MatchThreshold})
+	if err != nil {
+		return err
+	}
+
+	pinned, err := overrides.Load(o.OverridesFile)
+	if err != nil {
+		return err
+	}
+
+	limiter := ratelimit.New()
+	if err := ratelimit.ParseLimits(o.RateLimits, limiter.Limits); err != nil {
+		return err
+	}
+
+	opts := runOptions{
+		inputFile:    o.InputFile,
+		summaryFile:  o.SummaryFile,
+		startYear:    o.StartYear,
+		endYear:      o.EndYear,
+		platforms:    platforms,
+		cache:        cache,
+		resume:       o.Resume,
+		overrides:    pinned,
+		concurrency:  o.Concurrency,
+		limiter:      limiter,
+		upstream:     o.Upstream,
+		credentials:  o.Credentials,
+		genres:       o.Genres,
+		reportFile:   o.ReportFile,
+		thumbnailDir: o.ThumbnailDir,
+	}
+	if o.MusicBrainz {
+		opts.musicbrainzURL = o.Upstream.MusicBrainz
+		if o.MusicBrainzURL != "" {
+			opts.musicbrainzURL = o.MusicBrainzURL
+		}
+		opts.yearTolerance = o.YearTolerance
+	}
+	if o.Catalog != "" {
+		cat, err := catalog.Open(o.Catalog)
+		if err != nil {
+			return err
+		}
+		defer cat.Close()
+		opts.catalog, opts.deck = cat, o.Deck
+	}
+	return run(opts)
+}
+
+func run(opts runOptions) error {
+	cache := opts.cache
+
+	// Offline runs never talk to Spotify, so they need no credentials.
+	if !cache.offline {
+		if err := opts.credentials.Check(); err != nil {
+			return err
+		}
+	}
+
+	// 1. Setup Clients
+	// Cache hits skip the rate limiter; only real requests take a token.
+	// Cancelled on return, so that workers stop if the summary fails.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The cache sits in front of the OAuth transport so that cached
+	// responses are keyed without the bearer token and hits need no token.
+	// Requests that reach the network are rate limited below it.
+	spotifyClient := spotifyclient.New(ctx, opts.credentials, opts.upstream, spotifyclient.Options{
+		TokenTransport: opts.limiter.Wrap(nil),
+		Wrap:           cache.wrap,
+	})
+
+	retryClient := retryablehttp.NewClient()
+	retryClient.RetryMax = 5
+	retryClient.Logger = nil
+	retryClient.CheckRetry = checkRetry
+	retryClient.HTTPClient.Timeout = 15 * time.Second
+	retryClient.HTTPClient.Transport = cache.wrap(opts.limiter.Wrap(retryClient.HTTPClient.Transport))
+
+	c := &clients{
+		spotify:       spotifyClient,
+		http:          retryClient,
+		platforms:     opts.platforms,
+		odesliURL:     opts.upstream.Odesli,
+		yearTolerance: opts.yearTolerance,
+		genres:        opts.genres,
+		unmapped:      &genre.Unmapped{},
+		thumbnailDir:  opts.thumbnailDir,
+	}
+	if opts.musicbrainzURL != "" {
+		c.musicbrainz = musicbrainz.New(retryClient, opts.musicbrainzURL)
+	}
+
+	// 2. Read Input
+	songs, input, err := opts.input()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", input, err)
+	}
+
+	fmt.Printf("Loaded %d songs from %s\n", len(songs), input)
+
+	// Songs completed by a previous run, keyed by Spotify ID
+	var done map[string]models.GeneratedSong
+	if opts.resume {
+		previous, output, err := opts.previous()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", output, err)
+		}
+		done = completedSongs(previous)
+		fmt.Printf("Resuming: %d of %d songs in %s already completed\n", len(done), len(previous), output)
+	}
+
+	// Overrides pinned on each song, reported once the run is done
+	report := &overrides.Report{}
+	defer report.Write(os.Stdout, opts.overrides)
+
+	// 3. Queue each song in input order; completed songs keep their place
+	var slots []summarySlot
+	var jobs []lookupJob
+	var initial []models.GeneratedSong
+	for _, songInput := range songs {
+		if songInput.Year < opts.startYear || songInput.Year > opts.endYear {
+			continue
+		}
+
+		// A. Parse Spotify ID
+		spotifyID := spotifyPlatform.ParseID(songInput.URL)
+		if spotifyID == "" {
+			continue
+		}
+
+		slot := summarySlot{year: songInput.Year}
+		if genSong, ok := done[spotifyID]; ok {
+			opts.overrides.Apply(&genSong, report)
+			slot.song, slot.ready, slot.written = &genSong, true, true
+			initial = append(initial, genSong)
+		} else {
+			jobs = append(jobs, lookupJob{index: len(slots), input: songInput, spotifyID: spotifyID})
+		}
+		slots = append(slots, slot)
+	}
+
+	// Open summary file for streaming, starting with the completed songs
+	summary, err := opts.output(initial)
+	if err != nil {
+		return fmt.Errorf("failed to create summary: %w", err)
+	}
+
+	fmt.Printf("Looking up %d songs with %d workers\n", len(jobs), opts.concurrency)
+
+	// Write results in input order as soon as all earlier songs are done
+	next, currentYear := 0, 0
+	for result := range lookupAll(ctx, c, jobs, opts.concurrency) {
+		if result.song != nil {
+			opts.overrides.Apply(result.song, report)
+			// Thumbnails are named after the title and year, which
+			// an override may have changed
+			if err := fetchThumbnail(retryClient, opts.thumbnailDir, summarySong(result.song)); err != nil {
+				log.Printf("Failed to fetch thumbnail for %s: %v", result.song.Title, err)
+			}
+		}
+		slots[result.index].song = result.song
+		slots[result.index].ready = true
+
+		for ; next < len(slots) && slots[next].ready; next++ {
+			slot := &slots[next]
+			if slot.year != 0 && slot.year != currentYear {
+				currentYear = slot.year
+				fmt.Printf("Finished up to Year %d...\n", currentYear)
+			}
+			if slot.song == nil || slot.written {
+				continue
+			}
+			if err := summary.Write(*slot.song); err != nil {
+				summary.Close()
+				return err
+			}
+			slot.written = true
+		}
+	}
+
+	// Close JSON array
+	if err := summary.Close(); err != nil {
+		return err
+	}
+
+	var ordered []models.GeneratedSong
+	for _, slot := range slots {
+		if slot.song != nil {
+			ordered = append(ordered, *slot.song)
+		}
+	}
+
+	// Resumed runs wrote completed songs first; restore input order
+	if len(initial) > 0 && opts.catalog == nil {
+		if err := writeSummary(opts.summaryFile, ordered); err != nil {
+			return err
+		}
+	}
+	c.unmapped.Write(os.Stdout)
+	cache.report(os.Stdout)
+
+	if opts.reportFile != "" {
+		if err := writeReport(opts.reportFile, opts.thumbnailDir, opts.genres, ordered, opts.platforms); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("Wrote review report to %s\n", opts.reportFile)
+	}
+	return nil
+}
+
+// summarySong returns the fields of a summary entry that name its assets.
+func summarySong(g *models.GeneratedSong) *models.Song {
+	return &models.Song{Title: g.Title, Year: g.Year, ThumbnailURL: g.ThumbnailURL}
+}
+
+// summarySlot is a song's place in the summary while lookups complete out
+// of order.
+type summarySlot struct {
+	year    int
+	song    *models.GeneratedSong
+	ready   bool
+	written bool
+}
+
+func readInputLinks(path string) ([]models.CollectedSong, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var songs []models.CollectedSong
+
+	// Try to decode as []CollectedSong
+	if err := json.NewDecoder(f).Decode(&songs); err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
@@ -0,0 +1,115 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"temporalize/internal/config"
+	"temporalize/internal/fakes"
+	"temporalize/internal/match"
+	"temporalize/internal/models"
+	"temporalize/internal/policy"
+	"temporalize/internal/spotifyclient"
+)
+
+// testOptions returns options looking up the 1985 tracks ids from srv,
+// with the input and summary files in dir.
+func testOptions(t *testing.T, srv *fakes.Server, dir string, ids []string) Options {
+	t.Helper()
+	genres, err := config.Default().Taxonomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input []models.CollectedSong
+	for _, id := range ids {
+		input = append(input, models.CollectedSong{URL: "https://open.spotify.com/track/" + id, Genre: "rock", Year: 1985})
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputFile := filepath.Join(dir, "collect.json")
+	if err := os.WriteFile(inputFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Options{
+		InputFile:      inputFile,
+		SummaryFile:    filepath.Join(dir, "lookup.json"),
+		StartYear:      1985,
+		EndYear:        1985,
+		Concurrency:    2,
+		MatchThreshold: match.DefaultThreshold,
+		Upstream:       srv.Endpoints(),
+		Genres:         genres,
+		ThumbnailDir:   filepath.Join(dir, "thumbnails"),
+		Credentials:    spotifyclient.Credentials{ClientID: "id", ClientSecret: "secret"},
+	}
+}
+
+func TestRunWithoutSpotifyPlatform(t *testing.T) {
+	srv := fakes.New()
+	defer srv.Close()
+	opts := testOptions(t, srv, t.TempDir(), []string{"2WfaOiMkCvy7F5fcp2zZ8L"})
+	opts.Platforms = "deezer,appleMusic"
+	if err := Run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	songs, err := readSummary(opts.SummaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(songs) != 1 {
+		t.Fatalf("summary has %d songs, want 1", len(songs))
+	}
+	s := songs[0]
+	if p := s.Platforms[models.PlatformSpotify]; p.Status != models.StatusOK || p.Method != models.MethodSource {
+		t.Errorf("Spotify status = %+v, want ok from the source", p)
+	}
+
+	// Spotify plus the two platforms looked up.
+	p, err := policy.Parse(policy.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(&s); err != nil {
+		t.Errorf("policy rejected %s: %v", s.Title, err)
+	}
+	if !p.Usable(&s, models.PlatformSpotify) {
+		t.Error("Spotify link is not usable on the card")
+	}
+}
+
+func TestLookupAllStopsWhenCancelled(t *testing.T) {
+	srv := fakes.New()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &clients{spotify: spotifyclient.New(ctx, spotifyclient.Credentials{ClientID: "id", ClientSecret: "secret"}, srv.Endpoints(), spotifyclient.Options{})}
+
+	jobs := make([]lookupJob, 100)
+	for i := range jobs {
+		jobs[i] = lookupJob{index: i, spotifyID: "2WfaOiMkCvy7F5fcp2zZ8L"}
+	}
+	results := lookupAll(ctx, c, jobs, 2)
+	n := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				if n == len(jobs) {
+					t.Errorf("lookupAll looked up all %d songs after being cancelled", n)
+				}
+				return
+			}
+			n++
+		case <-timeout:
+			t.Fatal("lookupAll did not stop after being cancelled")
+		}
+	}
+}
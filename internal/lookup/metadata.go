@@ -1,4 +1,4 @@
-package main
+package lookup
 
 import (
 	"context"
@@ -8,30 +8,16 @@ import (
 	"os"
 	"strconv"
 
-	"strings"
-
+	"temporalize/internal/genre"
 	"temporalize/internal/models"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/zmb3/spotify/v2"
 )
 
-const (
-	thumbnailDir = "thumbnails"
-)
-
-// mapping of genres to subgenres for collection/categorization
-func genreGroups() map[string][]string {
-	return map[string][]string{
-		"pop":     {"pop", "dance", "electro", "synth", "r&b", "soul", "disco"},
-		"rock":    {"rock", "metal", "punk", "indie", "alternative"},
-		"hip-hop": {"hip hop", "rap", "trap"},
-		"country": {"country", "folk", "americana", "bluegrass"},
-		"jazz":    {"jazz", "blues", "funk"},
-	}
-}
-
-func fetchMetadata(ctx context.Context, client *spotify.Client, spotifyID, genreHint string) (*models.Song, error) {
+// fetchMetadata reads the track from Spotify. Songs whose artist genres do
+// not map are added to unmapped.
+func fetchMetadata(ctx context.Context, client *spotify.Client, genres *genre.Taxonomy, unmapped *genre.Unmapped, spotifyID, genreHint string) (*models.Song, error) {
 	track, err := client.GetTrack(ctx, spotify.ID(spotifyID))
 	if err != nil {
 		return nil, err
@@ -50,27 +36,15 @@ func fetchMetadata(ctx context.Context, client *spotify.Client, spotifyID, genre
 	}
 
 	// Get Genre
-	// If genreHint is provided and valid, use it.
-	// Otherwise try to infer from artist.
-	genre := genreHint
-	if genre == "" || genre == "default" {
-		genre = "default"
-		if len(track.Artists) > 0 {
-			artist, err := client.GetArtist(ctx, track.Artists[0].ID)
-			if err == nil && len(artist.Genres) > 0 {
-				rawGenre := artist.Genres[0]
-				// Map to our simplified genre groups
-				for group, subgenres := range genreGroups() {
-					for _, sg := range subgenres {
-						if strings.Contains(strings.ToLower(rawGenre), sg) {
-							genre = group
-							break
-						}
-					}
-					if genre != "default" {
-						break
-					}
-				}
+	// Collected songs carry their genre; others are classified by the
+	// first artist's Spotify genres.
+	songGenre := genres.Canonical(genreHint)
+	if songGenre == genre.Unknown && len(track.Artists) > 0 {
+		artist, err := client.GetArtist(ctx, track.Artists[0].ID)
+		if err == nil {
+			var ok bool
+			if songGenre, ok = genres.Classify(artist.Genres); !ok {
+				unmapped.Add(track.Name, artist.Genres)
 			}
 		}
 	}
@@ -87,17 +61,25 @@ func fetchMetadata(ctx context.Context, client *spotify.Client, spotifyID, genre
 		Year:         year,
 		Explicit:     track.Explicit,
 		Popularity:   int(track.Popularity),
-		Genre:        genre,
+		Genre:        songGenre,
 		Spotify:      spotifyID,
 		ThumbnailURL: thumbnailURL,
+		ISRC:         track.ExternalIDs["isrc"],
+		SpotifyYear:  year,
+		YearSource:   models.YearSourceSpotify,
 	}, nil
 }
 
-func fetchThumbnail(client *retryablehttp.Client, s *models.Song) error {
-	filename := fmt.Sprintf("%s/%s.jpeg", thumbnailDir, s.FileName())
+// thumbnailFile is where a song's album art is downloaded to.
+func thumbnailFile(dir string, s *models.Song) string {
+	return fmt.Sprintf("%s/%s.jpeg", dir, s.FileName())
+}
+
+func fetchThumbnail(client *retryablehttp.Client, dir string, s *models.Song) error {
+	filename := thumbnailFile(dir, s)
 
 	// Ensure directory exists
-	if err := os.MkdirAll(thumbnailDir, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
@@ -0,0 +1,37 @@
+package lookup
+
+import (
+	"context"
+	"testing"
+
+	"temporalize/internal/config"
+	"temporalize/internal/fakes"
+	"temporalize/internal/genre"
+	"temporalize/internal/spotifyclient"
+)
+
+func TestFetchMetadataISRC(t *testing.T) {
+	srv := fakes.New()
+	defer srv.Close()
+	ctx := context.Background()
+	client := spotifyclient.New(ctx, spotifyclient.Credentials{ClientID: "id", ClientSecret: "secret"}, srv.Endpoints(), spotifyclient.Options{})
+	genres, err := config.Default().Taxonomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ISRC the platforms are matched by comes from Spotify's external
+	// IDs.
+	for id, want := range map[string]string{
+		"2WfaOiMkCvy7F5fcp2zZ8L": "NOA018500011",
+		"3fH4KjXFYMmljxrcGrbPj9": "GBAAA8500123",
+	} {
+		song, err := fetchMetadata(ctx, client, genres, &genre.Unmapped{}, id, "pop")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if song.ISRC != want || song.Spotify != id {
+			t.Errorf("fetchMetadata(%s) ISRC = %q, want %q", id, song.ISRC, want)
+		}
+	}
+}
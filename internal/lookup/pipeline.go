@@ -0,0 +1,158 @@
+package lookup
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"temporalize/internal/genre"
+	"temporalize/internal/models"
+	"temporalize/internal/musicbrainz"
+	"temporalize/internal/platform"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/zmb3/spotify/v2"
+)
+
+// clients holds the API clients shared by the lookup workers.
+type clients struct {
+	spotify   *spotify.Client
+	http      *retryablehttp.Client
+	platforms []platform.Platform
+	odesliURL string
+	// musicbrainz resolves original release years; nil keeps Spotify's.
+	musicbrainz   *musicbrainz.Client
+	yearTolerance int
+	genres        *genre.Taxonomy
+	unmapped      *genre.Unmapped
+	thumbnailDir  string
+}
+
+// lookupJob is an input song and its position in the summary.
+type lookupJob struct {
+	index     int
+	input     models.CollectedSong
+	spotifyID string
+}
+
+type lookupResult struct {
+	index int
+	song  *models.GeneratedSong // nil if the lookup failed
+}
+
+// lookupAll looks songs up on the given number of workers and delivers
+// results in completion order. Once ctx is done no further songs are
+// started or delivered, and the channel is closed when the workers exit.
+func lookupAll(ctx context.Context, c *clients, jobs []lookupJob, workers int) <-chan lookupResult {
+	if workers < 1 {
+		workers = 1
+	}
+	queue := make(chan lookupJob)
+	results := make(chan lookupResult, len(jobs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				select {
+				case results <- lookupResult{index: job.index, song: lookupSong(ctx, c, job)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+	feed:
+		for _, job := range jobs {
+			select {
+			case queue <- job:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(queue)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// lookupSong fetches a song's metadata and links and validates them.
+// Failures are logged and reported as nil.
+func lookupSong(ctx context.Context, c *clients, job lookupJob) *models.GeneratedSong {
+	// B. Fetch Metadata (Spotify)
+	// We pass the collected genre to fetchMetadata
+	song, err := fetchMetadata(ctx, c.spotify, c.genres, c.unmapped, job.spotifyID, job.input.Genre)
+	if err != nil {
+		log.Printf("Failed to fetch metadata for %s: %v", job.input.URL, err)
+		return nil
+	}
+
+	// Clean the title before using it
+	song.OriginalTitle = song.Title
+	song.Title = cleanTitle(song.Title)
+
+	// Date remasters and compilations by their original release
+	resolveYear(c.musicbrainz, song)
+
+	// C. Fetch Thumbnail
+	if err := fetchThumbnail(c.http, c.thumbnailDir, song); err != nil {
+		log.Printf("Failed to fetch thumbnail for %s: %v", song.Title, err)
+	}
+
+	// D. Fetch Other Links (Odesli)
+	linksMap, err := fetchLinks(c.http, c.odesliURL, job.spotifyID, c.platforms)
+	if err != nil {
+		log.Printf("Failed to fetch links for %s: %v", song.Title, err)
+		return nil
+	}
+
+	// E. Validate & Fix Links
+	// Map Odesli links to our Song struct fields
+	for key, id := range linksMap {
+		song.SetLink(key, id)
+	}
+	song.Spotify = job.spotifyID // Ensure ID is set
+
+	// fixLinks modifies the song object in place
+	isValid := fixLinks(c.http, song, c.platforms)
+
+	// Construct output object
+	genSong := &models.GeneratedSong{
+		Explicit:     song.Explicit,
+		Year:         song.Year,
+		Artists:      song.Artists,
+		Genre:        song.Genre,
+		Title:        song.Title,
+		ThumbnailURL: song.ThumbnailURL,
+		Spotify:      spotifyPlatform.URL(song.Spotify),
+		Invalid:      !isValid,
+		ISRC:         song.ISRC,
+		SpotifyYear:  song.SpotifyYear,
+		OriginalYear: song.OriginalYear,
+		YearSource:   song.YearSource,
+		YearMismatch: yearMismatch(song, c.yearTolerance),
+	}
+	if song.OriginalTitle != song.Title {
+		genSong.OriginalTitle = song.OriginalTitle
+	}
+	for _, p := range c.platforms {
+		if id := song.Link(p.Key()); id != "" {
+			genSong.SetLink(p.Key(), p.URL(id))
+		}
+		if status, ok := song.Platforms[p.Key()]; ok {
+			genSong.SetStatus(p.Key(), status)
+		}
+	}
+	// Spotify is the source of every song, so its link stands even when
+	// it was not among the platforms checked.
+	if _, ok := genSong.Platforms[models.PlatformSpotify]; !ok {
+		genSong.SetStatus(models.PlatformSpotify, models.PlatformStatus{Status: models.StatusOK, Method: models.MethodSource, Score: 1})
+	}
+	return genSong
+}
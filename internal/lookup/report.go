@@ -0,0 +1,132 @@
+package lookup
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"os"
+	"strings"
+	"time"
+
+	"temporalize/internal/genre"
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+// reportThumbSize is the edge of the thumbnails inlined in the report, small
+// enough that a full deck stays a few megabytes.
+const reportThumbSize = 96
+
+type reportData struct {
+	Generated time.Time
+	Platforms []string
+	Songs     []reportSong
+	Counts    map[string]int
+}
+
+type reportSong struct {
+	*models.GeneratedSong
+	Thumbnail template.URL
+	// GenreName is the genre's display name.
+	GenreName string
+	Links     []reportLink
+	// Flags are the filter classes the row belongs to.
+	Flags string
+}
+
+type reportLink struct {
+	Name string
+	URL  string
+	models.PlatformStatus
+}
+
+// writeReport writes a self-contained HTML page for reviewing a lookup run:
+// every song with its thumbnail, years, titles and per-platform status,
+// filterable by problem.
+func writeReport(path, thumbnailDir string, genres *genre.Taxonomy, songs []models.GeneratedSong, platforms []platform.Platform) error {
+	data := reportData{Generated: time.Now(), Counts: make(map[string]int)}
+	for _, p := range platforms {
+		data.Platforms = append(data.Platforms, p.Name())
+	}
+
+	for i := range songs {
+		g := &songs[i]
+		rs := reportSong{GeneratedSong: g, Thumbnail: reportThumbnail(thumbnailDir, g), GenreName: genres.Display(g.Genre)}
+		flags := map[string]bool{}
+		for _, p := range platforms {
+			status, ok := g.Platforms[p.Key()]
+			if !ok && g.Link(p.Key()) != "" {
+				status.Status = models.StatusOK
+			}
+			rs.Links = append(rs.Links, reportLink{Name: p.Name(), URL: g.Link(p.Key()), PlatformStatus: status})
+			if status.Status != "" && status.Status != models.StatusOK {
+				flags[status.Status] = true
+			}
+		}
+		if g.Invalid {
+			flags["invalid"] = true
+		}
+		if g.YearMismatch {
+			flags["year-mismatch"] = true
+		}
+		if g.OriginalTitle != "" {
+			flags["cleaned"] = true
+		}
+		if genres.Canonical(g.Genre) == genre.Unknown {
+			flags["unmapped"] = true
+		}
+		if g.Excluded {
+			flags["excluded"] = true
+		}
+		if len(g.Overrides) > 0 {
+			flags["override"] = true
+		}
+		for _, flag := range []string{"invalid", models.StatusFailed, models.StatusFixed, models.StatusUnverified, "year-mismatch", "cleaned", "unmapped", "override", "excluded"} {
+			if flags[flag] {
+				rs.Flags += " " + flag
+				data.Counts[flag]++
+			}
+		}
+		rs.Flags = strings.TrimSpace(rs.Flags)
+		data.Songs = append(data.Songs, rs)
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// reportThumbnail inlines a downscaled copy of the song's downloaded
+// thumbnail, falling back to the remote URL.
+func reportThumbnail(dir string, g *models.GeneratedSong) template.URL {
+	f, err := os.Open(thumbnailFile(dir, summarySong(g)))
+	if err != nil {
+		return template.URL(g.ThumbnailURL)
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return template.URL(g.ThumbnailURL)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, reportThumbSize, reportThumbSize))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 75}); err != nil {
+		return template.URL(g.ThumbnailURL)
+	}
+	return template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
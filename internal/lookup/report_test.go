@@ -0,0 +1,87 @@
+package lookup
+
+import (
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"temporalize/internal/config"
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+)
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	genres, err := config.Default().Taxonomy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	platforms, err := platform.Select("spotify,deezer", platform.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	songs := []models.GeneratedSong{
+		{
+			Title: "Take On Me", Year: 1985, Genre: "pop",
+			Spotify:   "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+			Deezer:    "https://www.deezer.com/track/664107",
+			Platforms: map[string]models.PlatformStatus{models.PlatformDeezer: {Status: models.StatusOK, Method: models.MethodISRC}},
+		},
+		{
+			Title: "Don't You <script>alert(1)</script>", Year: 1985, Genre: "rock",
+			OriginalTitle: "Don't You - Remastered 2003",
+			YearMismatch:  true,
+			Invalid:       true,
+			ThumbnailURL:  "https://i.scdn.co/image/dont-you",
+			Spotify:       "https://open.spotify.com/track/3fH4KjXFYMmljxrcGrbPj9",
+			Platforms: map[string]models.PlatformStatus{
+				models.PlatformSpotify: {Status: models.StatusFixed, Method: models.MethodSearch},
+				models.PlatformDeezer:  {Status: models.StatusFailed, Reason: "no results"},
+			},
+		},
+	}
+
+	// Only the first song has a downloaded thumbnail.
+	thumbDir := filepath.Join(dir, "thumbnails")
+	if err := os.MkdirAll(thumbDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(thumbnailFile(thumbDir, summarySong(&songs[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(f, image.NewRGBA(image.Rect(0, 0, 300, 300)), nil); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	path := filepath.Join(dir, "report.html")
+	if err := writeReport(path, thumbDir, genres, songs, platforms); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		`<tr data-flags="">`,
+		`<tr data-flags="invalid failed fixed year-mismatch cleaned">`,
+		`Invalid (1)`, `Failed (1)`, `Fixed (1)`, `Unverified (0)`, `Year mismatch (1)`, `Cleaned title (1)`,
+		`src="data:image/jpeg;base64,`,
+		`src="https://i.scdn.co/image/dont-you"`,
+		`Don&#39;t You &lt;script&gt;alert(1)&lt;/script&gt;`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(report, "<script>alert") {
+		t.Error("report does not escape titles")
+	}
+}
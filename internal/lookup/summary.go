@@ -0,0 +1,202 @@
+package lookup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"temporalize/internal/catalog"
+	"temporalize/internal/models"
+)
+
+// summaryWriter streams songs into the summary file as a JSON array, so a
+// crash loses at most the song being written.
+type summaryWriter struct {
+	f       *os.File
+	encoder *json.Encoder
+	empty   bool
+}
+
+// createSummary starts a summary file holding initial. The initial entries
+// are written to a temporary file and renamed into place, so an existing
+// summary is never lost half way through being rewritten.
+func createSummary(path string, initial []models.GeneratedSong) (*summaryWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	w := newSummaryWriter(tmp)
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if _, err := tmp.WriteString("[\n"); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	for _, s := range initial {
+		if err := w.Write(s); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return w, nil
+}
+
+func newSummaryWriter(f *os.File) *summaryWriter {
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("  ", "  ")
+	return &summaryWriter{f: f, encoder: encoder, empty: true}
+}
+
+func (w *summaryWriter) Write(s models.GeneratedSong) error {
+	if !w.empty {
+		if _, err := w.f.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	w.empty = false
+	return w.encoder.Encode(s)
+}
+
+// Close terminates the JSON array and closes the file.
+func (w *summaryWriter) Close() error {
+	if _, err := w.f.WriteString("\n]"); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// writeSummary replaces the summary file with songs.
+func writeSummary(path string, songs []models.GeneratedSong) error {
+	w, err := createSummary(path, songs)
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// readSummary reads the songs in an existing summary file. A file cut short
+// by a crash is accepted: every complete entry before the cut is returned.
+// A missing file yields no songs.
+func readSummary(path string) ([]models.GeneratedSong, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('[') {
+		return nil, fmt.Errorf("%s is not a JSON array", path)
+	}
+
+	var songs []models.GeneratedSong
+	for dec.More() {
+		var s models.GeneratedSong
+		if err := dec.Decode(&s); err != nil {
+			// Truncated tail; keep what was complete.
+			break
+		}
+		songs = append(songs, s)
+	}
+	return songs, nil
+}
+
+// completedSongs indexes the valid songs of a previous run by Spotify ID.
+// Songs marked Invalid are left out so that they are looked up again.
+func completedSongs(songs []models.GeneratedSong) map[string]models.GeneratedSong {
+	done := make(map[string]models.GeneratedSong)
+	for _, s := range songs {
+		if s.Invalid {
+			continue
+		}
+		if id := spotifyPlatform.ParseID(s.Spotify); id != "" {
+			done[id] = s
+		}
+	}
+	return done
+}
+
+// summaryOutput receives the looked up songs in input order.
+type summaryOutput interface {
+	Write(s models.GeneratedSong) error
+	Close() error
+}
+
+// input returns the collected songs and where they were read from.
+func (opts runOptions) input() ([]models.CollectedSong, string, error) {
+	if opts.catalog != nil {
+		songs, err := opts.catalog.Collected(opts.deck)
+		return songs, "deck " + opts.deck, err
+	}
+	songs, err := readInputLinks(opts.inputFile)
+	return songs, opts.inputFile, err
+}
+
+// previous returns the songs of earlier runs and where they were read from.
+func (opts runOptions) previous() ([]models.GeneratedSong, string, error) {
+	if opts.catalog != nil {
+		songs, err := opts.catalog.Summaries(opts.deck)
+		return songs, "deck " + opts.deck, err
+	}
+	songs, err := readSummary(opts.summaryFile)
+	return songs, opts.summaryFile, err
+}
+
+// output starts the summary file with the completed songs, or writes to the
+// catalogue, which holds them already.
+func (opts runOptions) output(initial []models.GeneratedSong) (summaryOutput, error) {
+	if opts.catalog != nil {
+		return catalogOutput{cat: opts.catalog, thumbnailDir: opts.thumbnailDir}, nil
+	}
+	return createSummary(opts.summaryFile, initial)
+}
+
+// catalogOutput stores each song and its thumbnail in the catalogue.
+type catalogOutput struct {
+	cat          *catalog.Catalog
+	thumbnailDir string
+}
+
+func (o catalogOutput) Write(s models.GeneratedSong) error {
+	if err := o.cat.PutSummary(s); err != nil {
+		return err
+	}
+	file := thumbnailFile(o.thumbnailDir, summarySong(&s))
+	if _, err := os.Stat(file); err != nil {
+		// Not downloaded; the failure was logged.
+		return nil
+	}
+	return o.cat.PutThumbnail(catalog.SongID(s.Spotify), s.ThumbnailURL, file)
+}
+
+// Close leaves the catalogue open for Run to close.
+func (o catalogOutput) Close() error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package lookup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"temporalize/internal/fakes"
+	"temporalize/internal/models"
+)
+
+func TestReadSummaryTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.json")
+	songs := []models.GeneratedSong{
+		{Title: "Take On Me", Year: 1985, Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L"},
+		{Title: "Money for Nothing", Year: 1985, Spotify: "https://open.spotify.com/track/4yQw7FR9lcvL6RHtegbJBh"},
+	}
+	if err := writeSummary(path, songs); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut the file in the middle of the second entry, as a crash would.
+	cut := strings.Index(string(data), "Money for")
+	if err := os.WriteFile(path, data[:cut], 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readSummary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Title != "Take On Me" {
+		t.Errorf("readSummary of a cut file = %+v, want only Take On Me", got)
+	}
+
+	// An unterminated array of complete entries loses nothing.
+	cut = strings.LastIndex(string(data), "]")
+	if err := os.WriteFile(path, data[:cut], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readSummary(path); err != nil || len(got) != 2 {
+		t.Errorf("readSummary of an unterminated file = %d songs, %v, want 2", len(got), err)
+	}
+}
+
+func TestReadSummaryRejects(t *testing.T) {
+	dir := t.TempDir()
+	if songs, err := readSummary(filepath.Join(dir, "missing.json")); err != nil || songs != nil {
+		t.Errorf("readSummary of a missing file = %v, %v, want nothing", songs, err)
+	}
+
+	path := filepath.Join(dir, "object.json")
+	if err := os.WriteFile(path, []byte(`{"title": "Take On Me"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSummary(path); err == nil {
+		t.Error("readSummary accepted a JSON object")
+	}
+}
+
+func TestCompletedSongs(t *testing.T) {
+	done := completedSongs([]models.GeneratedSong{
+		{Title: "Take On Me", Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L"},
+		{Title: "Don't You (Forget About Me)", Spotify: "https://open.spotify.com/track/3fH4KjXFYMmljxrcGrbPj9", Invalid: true},
+		{Title: "No Link"},
+	})
+	if len(done) != 1 || done["2WfaOiMkCvy7F5fcp2zZ8L"].Title != "Take On Me" {
+		t.Errorf("completedSongs = %v, want only Take On Me", done)
+	}
+}
+
+func TestResumeKeepsInputOrder(t *testing.T) {
+	srv := fakes.New()
+	defer srv.Close()
+	dir := t.TempDir()
+	ids := []string{"2WfaOiMkCvy7F5fcp2zZ8L", "3fH4KjXFYMmljxrcGrbPj9", "4yQw7FR9lcvL6RHtegbJBh"}
+	opts := testOptions(t, srv, dir, ids)
+	opts.Resume = true
+
+	// The last song is complete; the second was invalid and is retried.
+	if err := writeSummary(opts.SummaryFile, []models.GeneratedSong{
+		{Title: "Money for Nothing (resumed)", Year: 1985, Spotify: "https://open.spotify.com/track/" + ids[2]},
+		{Title: "Don't You (Forget About Me)", Year: 1985, Spotify: "https://open.spotify.com/track/" + ids[1], Invalid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	songs, err := readSummary(opts.SummaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Take On Me", "Don't You (Forget About Me)", "Money for Nothing (resumed)"}
+	if len(songs) != len(want) {
+		t.Fatalf("summary has %d songs, want %d", len(songs), len(want))
+	}
+	for i, s := range songs {
+		if s.Title != want[i] {
+			t.Errorf("summary song %d = %q, want %q", i, s.Title, want[i])
+		}
+	}
+	for _, r := range srv.Requests() {
+		if strings.Contains(r, "/tracks/"+ids[2]) {
+			t.Errorf("the completed song was looked up again: %s", r)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package lookup
+
+import (
+	"errors"
+	"log"
+
+	"temporalize/internal/models"
+	"temporalize/internal/musicbrainz"
+)
+
+// resolveYear looks up the song's original release year on MusicBrainz and
+// uses it when it is earlier than the Spotify album year.
+func resolveYear(mb *musicbrainz.Client, song *models.Song) {
+	if mb == nil || song.ISRC == "" {
+		return
+	}
+
+	year, err := mb.OriginalYear(song.ISRC)
+	if err != nil {
+		if !errors.Is(err, musicbrainz.ErrNotFound) {
+			log.Printf("Failed to resolve original year for %s: %v", song.Title, err)
+		}
+		return
+	}
+
+	song.OriginalYear = year
+	if song.Year == 0 || year < song.Year {
+		song.Year = year
+		song.YearSource = models.YearSourceMusicBrainz
+	}
+}
+
+// yearMismatch reports whether the Spotify and original years differ by
+// more than tolerance.
+func yearMismatch(song *models.Song, tolerance int) bool {
+	if song.SpotifyYear == 0 || song.OriginalYear == 0 {
+		return false
+	}
+	diff := song.SpotifyYear - song.OriginalYear
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff > tolerance
+}
@@ -0,0 +1,172 @@
+// Package match scores how closely a title or artist reported by a platform
+// matches the expected one. Scores run from 0 (unrelated) to 1 (same after
+// normalisation).
+package match
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the score below which a candidate is rejected.
+const DefaultThreshold = 0.8
+
+// coverageWeight caps the score of a candidate that merely contains every
+// expected word among others, e.g. "Queen - Bohemian Rhapsody (Official
+// Video)" for "Bohemian Rhapsody".
+const coverageWeight = 0.85
+
+// articles are dropped so that "The Beatles" matches "Beatles".
+var articles = map[string]bool{"the": true, "a": true, "an": true}
+
+// Normalize folds accents and case, spells out "&", drops apostrophes,
+// turns other punctuation into spaces and removes articles.
+func Normalize(s string) string {
+	return strings.Join(tokens(s), " ")
+}
+
+func tokens(s string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		r = fold(r)
+		switch {
+		case r == '&':
+			b.WriteString(" and ")
+		case r == '\'' || r == '’' || r == '`':
+			// "Don't" and "Dont" are the same word
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	var out []string
+	for _, t := range strings.Fields(b.String()) {
+		if !articles[t] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Score returns how well got matches want: the best of the edit distance
+// similarity, the token set overlap and, at a discount, the share of want's
+// words found in got.
+func Score(want, got string) float64 {
+	w, g := tokens(want), tokens(got)
+	if len(w) == 0 || len(g) == 0 {
+		return 0
+	}
+	ws, gs := strings.Join(w, " "), strings.Join(g, " ")
+	if ws == gs {
+		return 1
+	}
+
+	gotSet := make(map[string]bool, len(g))
+	for _, t := range g {
+		gotSet[t] = true
+	}
+	common := 0
+	seen := make(map[string]bool, len(w))
+	for _, t := range w {
+		if gotSet[t] && !seen[t] {
+			common++
+		}
+		seen[t] = true
+	}
+
+	score := similarity(ws, gs)
+	if dice := 2 * float64(common) / float64(len(seen)+len(gotSet)); dice > score {
+		score = dice
+	}
+	if coverage := coverageWeight * float64(common) / float64(len(seen)); coverage > score {
+		score = coverage
+	}
+	return score
+}
+
+// Best returns the highest score of want against any of the candidates.
+func Best(want string, candidates ...string) float64 {
+	best := 0.0
+	for _, c := range candidates {
+		if s := Score(want, c); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// similarity is 1 minus the Levenshtein distance relative to the longer
+// string.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// foldTable maps accented Latin letters to their base letter.
+var foldTable = func() map[rune]rune {
+	pairs := []string{
+		"àáâãäåāăą", "a",
+		"çćĉċč", "c",
+		"ďđ", "d",
+		"èéêëēĕėęě", "e",
+		"ĝğġģ", "g",
+		"ĥħ", "h",
+		"ìíîïĩīĭįı", "i",
+		"ĵ", "j",
+		"ķ", "k",
+		"ĺļľŀł", "l",
+		"ñńņňŉ", "n",
+		"òóôõöøōŏő", "o",
+		"ŕŗř", "r",
+		"śŝşšș", "s",
+		"ţťŧț", "t",
+		"ùúûüũūŭůűų", "u",
+		"ŵ", "w",
+		"ýÿŷ", "y",
+		"źżž", "z",
+	}
+	t := make(map[rune]rune)
+	for i := 0; i < len(pairs); i += 2 {
+		base := []rune(pairs[i+1])[0]
+		for _, r := range pairs[i] {
+			t[r] = base
+		}
+	}
+	return t
+}()
+
+func fold(r rune) rune {
+	if f, ok := foldTable[r]; ok {
+		return f
+	}
+	return r
+}
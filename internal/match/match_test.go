@@ -0,0 +1,63 @@
+package match
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Don't Stop Me Now":    "dont stop me now",
+		"Dont Stop Me Now":     "dont stop me now",
+		"The Beatles":          "beatles",
+		"Beyoncé":              "beyonce",
+		"Simon & Garfunkel":    "simon and garfunkel",
+		"Sigur Rós":            "sigur ros",
+		"Mötley Crüe":          "motley crue",
+		"AC/DC":                "ac dc",
+		"  Hello,   World!  ":  "hello world",
+		"A Day in the Life":    "day in life",
+		"Rock ’n’ Roll Star":   "rock n roll star",
+		"Ça plane pour moi":    "ca plane pour moi",
+		"Björk":                "bjork",
+		"99 Luftballons":       "99 luftballons",
+		"Jay-Z":                "jay z",
+		"":                     "",
+		"The":                  "",
+		"Motörhead & The Pack": "motorhead and pack",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		want, got string
+		min, max  float64
+	}{
+		{"Don't Stop Me Now", "Dont Stop Me Now", 1, 1},
+		{"The Beatles", "Beatles", 1, 1},
+		{"Beyoncé", "Beyonce", 1, 1},
+		{"Bohemian Rhapsody", "Queen - Bohemian Rhapsody (Official Video)", DefaultThreshold, 0.9},
+		{"Bohemian Rhapsody", "Bohemian Rhapsody - Remastered 2011", DefaultThreshold, 0.9},
+		{"Yesterday", "Yesterdays", DefaultThreshold, 0.95},
+		{"Bohemian Rhapsody", "Radio Ga Ga", 0, 0.5},
+		{"Hey Jude", "Let It Be", 0, 0.5},
+		{"", "anything", 0, 0},
+	}
+	for _, tt := range tests {
+		got := Score(tt.want, tt.got)
+		if got < tt.min || got > tt.max {
+			t.Errorf("Score(%q, %q) = %.3f, want in [%.2f, %.2f]", tt.want, tt.got, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	if got := Best("Queen", "Radio Ga Ga", "Queen Official", "Queen"); got != 1 {
+		t.Errorf("Best = %.3f, want 1", got)
+	}
+	if got := Best("Queen"); got != 0 {
+		t.Errorf("Best with no candidates = %.3f, want 0", got)
+	}
+}
@@ -6,6 +6,22 @@ import (
 	"strings"
 )
 
+// Platform keys, matching the names Odesli uses in linksByPlatform.
+const (
+	PlatformSpotify      = "spotify"
+	PlatformAppleMusic   = "appleMusic"
+	PlatformAmazonMusic  = "amazonMusic"
+	PlatformYoutubeMusic = "youtubeMusic"
+	PlatformDeezer       = "deezer"
+	PlatformTidal        = "tidal"
+)
+
+// Where a song's Year came from.
+const (
+	YearSourceSpotify     = "spotify"
+	YearSourceMusicBrainz = "musicbrainz"
+)
+
 type Song struct {
 	Year         int
 	Genre        string
@@ -17,7 +33,112 @@ type Song struct {
 	YoutubeMusic string
 	AppleMusic   string
 	AmazonMusic  string
+	Deezer       string
+	Tidal        string
 	ThumbnailURL string
+	ISRC         string
+	// SpotifyYear is the release year of the Spotify album, which may be a
+	// remaster or compilation. OriginalYear is the first release year from
+	// MusicBrainz, or 0 if unknown.
+	SpotifyYear  int
+	OriginalYear int
+	YearSource   string
+	// Platforms records the validation outcome of each platform's ID,
+	// keyed by platform key.
+	Platforms map[string]PlatformStatus
+	// OriginalTitle is the title Spotify reported, before cleaning.
+	OriginalTitle string
+}
+
+// Match records how a platform ID was matched to the song.
+type Match struct {
+	// Method is "isrc", "text" or "source".
+	Method string `json:"method"`
+	// Score is the confidence in [0, 1]; text matches carry their
+	// similarity score, the others 1.
+	Score float64 `json:"score"`
+}
+
+// Validation outcomes of a platform link.
+const (
+	// StatusOK means the looked up link matched the song.
+	StatusOK = "ok"
+	// StatusFixed means the looked up link was missing or wrong and was
+	// replaced by a search result.
+	StatusFixed = "fixed"
+	// StatusUnverified means the link was kept but the platform could not
+	// be reached to check it.
+	StatusUnverified = "unverified"
+	// StatusFailed means no matching link was found; the link is empty.
+	StatusFailed = "failed"
+)
+
+// Where a platform link came from.
+const (
+	MethodSource   = "source"
+	MethodOdesli   = "odesli"
+	MethodISRC     = "isrc"
+	MethodSearch   = "search"
+	MethodOverride = "override"
+)
+
+// PlatformStatus is the validation outcome of one platform link.
+type PlatformStatus struct {
+	Status string `json:"status"`
+	// Method is how the link was found: "source" for Spotify, "odesli",
+	// "isrc" when it was confirmed or found by ISRC, "search" or
+	// "override".
+	Method string `json:"method,omitempty"`
+	// Score is the match confidence in [0, 1].
+	Score float64 `json:"score,omitempty"`
+	// Reason explains why a link was fixed, unverified or failed.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Verified reports whether the link is known to point at the song.
+func (p PlatformStatus) Verified() bool {
+	return p.Status == StatusOK || p.Status == StatusFixed
+}
+
+// SetStatus records the validation outcome for the platform key.
+func (s *Song) SetStatus(key string, p PlatformStatus) {
+	if s.Platforms == nil {
+		s.Platforms = make(map[string]PlatformStatus)
+	}
+	s.Platforms[key] = p
+}
+
+// Link returns the compact platform ID stored for the platform key.
+func (s *Song) Link(key string) string {
+	if f := s.linkField(key); f != nil {
+		return *f
+	}
+	return ""
+}
+
+// SetLink stores the compact platform ID for the platform key.
+func (s *Song) SetLink(key, id string) {
+	if f := s.linkField(key); f != nil {
+		*f = id
+	}
+}
+
+func (s *Song) linkField(key string) *string {
+	switch key {
+	case PlatformSpotify:
+		return &s.Spotify
+	case PlatformAppleMusic:
+		return &s.AppleMusic
+	case PlatformAmazonMusic:
+		return &s.AmazonMusic
+	case PlatformYoutubeMusic:
+		return &s.YoutubeMusic
+	case PlatformDeezer:
+		return &s.Deezer
+	case PlatformTidal:
+		return &s.Tidal
+	}
+	return nil
 }
 
 func (s *Song) FileName() string {
@@ -30,6 +151,13 @@ func (s *Song) FileName() string {
 	return regexp.MustCompile(`_+`).ReplaceAllString(sanitized, "_")
 }
 
+// CollectedSong is an entry in the collect output read by lookup
+type CollectedSong struct {
+	URL   string `json:"url"`
+	Genre string `json:"genre"`
+	Year  int    `json:"year"`
+}
+
 // GeneratedSong represents the output summary for a song from the lookup process
 type GeneratedSong struct {
 	Explicit     bool     `json:"explicit"`
@@ -42,5 +170,64 @@ type GeneratedSong struct {
 	AppleMusic   string   `json:"apple_music"`
 	AmazonMusic  string   `json:"amazon_music"`
 	YoutubeMusic string   `json:"youtube_music"`
+	Deezer       string   `json:"deezer"`
+	Tidal        string   `json:"tidal"`
 	Invalid      bool     `json:"invalid"`
+	ISRC         string   `json:"isrc,omitempty"`
+	SpotifyYear  int      `json:"spotify_year,omitempty"`
+	OriginalYear int      `json:"original_year,omitempty"`
+	YearSource   string   `json:"year_source,omitempty"`
+	// YearMismatch flags songs whose Spotify and original years disagree by
+	// more than the lookup tolerance, for manual review.
+	YearMismatch bool `json:"year_mismatch,omitempty"`
+	// Platforms maps platform keys to the validation outcome of their link.
+	// Invalid is set when any of them failed.
+	Platforms map[string]PlatformStatus `json:"platforms,omitempty"`
+	// OriginalTitle is the Spotify title, set when cleaning changed it.
+	OriginalTitle string `json:"original_title,omitempty"`
+	// Excluded songs were dropped from the deck by an override.
+	Excluded bool `json:"excluded,omitempty"`
+	// Overrides lists the fields pinned by the overrides file.
+	Overrides []string `json:"overrides,omitempty"`
+}
+
+// SetStatus records the validation outcome for the platform key.
+func (g *GeneratedSong) SetStatus(key string, p PlatformStatus) {
+	if g.Platforms == nil {
+		g.Platforms = make(map[string]PlatformStatus)
+	}
+	g.Platforms[key] = p
+}
+
+// Link returns the URL stored for the platform key.
+func (g *GeneratedSong) Link(key string) string {
+	if f := g.linkField(key); f != nil {
+		return *f
+	}
+	return ""
+}
+
+// SetLink stores the URL for the platform key.
+func (g *GeneratedSong) SetLink(key, url string) {
+	if f := g.linkField(key); f != nil {
+		*f = url
+	}
+}
+
+func (g *GeneratedSong) linkField(key string) *string {
+	switch key {
+	case PlatformSpotify:
+		return &g.Spotify
+	case PlatformAppleMusic:
+		return &g.AppleMusic
+	case PlatformAmazonMusic:
+		return &g.AmazonMusic
+	case PlatformYoutubeMusic:
+		return &g.YoutubeMusic
+	case PlatformDeezer:
+		return &g.Deezer
+	case PlatformTidal:
+		return &g.Tidal
+	}
+	return nil
 }
@@ -0,0 +1,107 @@
+// Package musicbrainz looks up when a recording was first released, so that
+// remasters and compilations are dated by the original song rather than by
+// the album Spotify happens to carry.
+package musicbrainz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// DefaultBaseURL is the public MusicBrainz web service.
+const DefaultBaseURL = "https://musicbrainz.org"
+
+// userAgent identifies the application, as MusicBrainz requires.
+const userAgent = "Temporalize/1.0 ( https://github.com/Zambozoo/Temporalize )"
+
+var ErrNotFound = errors.New("not found on musicbrainz")
+
+// Client queries the MusicBrainz web service. MusicBrainz allows about one
+// request per second, which callers are expected to enforce on HTTP.
+type Client struct {
+	// BaseURL is the service root, e.g. DefaultBaseURL or a fixture server.
+	BaseURL string
+	HTTP    *retryablehttp.Client
+}
+
+// New returns a client for the service at baseURL.
+func New(httpClient *retryablehttp.Client, baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTP: httpClient}
+}
+
+type isrcResponse struct {
+	Recordings []struct {
+		Title            string `json:"title"`
+		FirstReleaseDate string `json:"first-release-date"`
+		Releases         []struct {
+			Date string `json:"date"`
+		} `json:"releases"`
+	} `json:"recordings"`
+}
+
+// OriginalYear returns the year of the earliest release of any recording
+// carrying the ISRC.
+func (c *Client) OriginalYear(isrc string) (int, error) {
+	u := fmt.Sprintf("%s/ws/2/isrc/%s?inc=releases&fmt=json", c.BaseURL, url.PathEscape(isrc))
+	req, err := retryablehttp.NewRequest("GET", u, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("isrc %s: %w", isrc, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("musicbrainz status %d", resp.StatusCode)
+	}
+
+	var result isrcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+
+	earliest := 0
+	consider := func(date string) {
+		if year := dateYear(date); year != 0 && (earliest == 0 || year < earliest) {
+			earliest = year
+		}
+	}
+	for _, rec := range result.Recordings {
+		consider(rec.FirstReleaseDate)
+		for _, rel := range rec.Releases {
+			consider(rel.Date)
+		}
+	}
+	if earliest == 0 {
+		return 0, fmt.Errorf("isrc %s has no dated releases: %w", isrc, ErrNotFound)
+	}
+	return earliest, nil
+}
+
+// dateYear parses the year of a MusicBrainz date, which may be "YYYY",
+// "YYYY-MM" or "YYYY-MM-DD". Unknown dates yield 0.
+func dateYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil || year <= 0 {
+		return 0
+	}
+	return year
+}
@@ -0,0 +1,88 @@
+package musicbrainz
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// fixtureServer serves canned /ws/2/isrc responses keyed by ISRC.
+func fixtureServer(t *testing.T, fixtures map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), userAgent)
+		}
+		isrc := r.URL.Path[len("/ws/2/isrc/"):]
+		body, ok := fixtures[isrc]
+		if !ok {
+			http.Error(w, `{"error":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(srv *httptest.Server) *Client {
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryMax = 0
+	httpClient.Logger = nil
+	return New(httpClient, srv.URL+"/")
+}
+
+func TestOriginalYear(t *testing.T) {
+	srv := fixtureServer(t, map[string]string{
+		// A 2011 remaster whose recording first appeared in 1975.
+		"GBUM71029604": `{"recordings":[
+			{"title":"Bohemian Rhapsody","first-release-date":"1975-10-31","releases":[
+				{"date":"2011-03-14"},{"date":"1975-10-31"},{"date":""}]},
+			{"title":"Bohemian Rhapsody (remastered)","releases":[{"date":"2011"}]}]}`,
+		// Only release dates, partially known.
+		"USRC17607839": `{"recordings":[{"title":"x","releases":[{"date":"1999-05"},{"date":"????"},{"date":"2004-01-01"}]}]}`,
+		"NODATE000001": `{"recordings":[{"title":"x","releases":[{"date":""}]}]}`,
+	})
+	c := newTestClient(srv)
+
+	tests := []struct {
+		isrc    string
+		want    int
+		wantErr error
+	}{
+		{"GBUM71029604", 1975, nil},
+		{"USRC17607839", 1999, nil},
+		{"NODATE000001", 0, ErrNotFound},
+		{"MISSING00001", 0, ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := c.OriginalYear(tt.isrc)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("OriginalYear(%s) error = %v, want %v", tt.isrc, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("OriginalYear(%s) = %d, want %d", tt.isrc, got, tt.want)
+		}
+	}
+}
+
+func TestDateYear(t *testing.T) {
+	tests := map[string]int{
+		"1975-10-31": 1975,
+		"1975-10":    1975,
+		"1975":       1975,
+		"":           0,
+		"19":         0,
+		"abcd-01-01": 0,
+		"0000":       0,
+	}
+	for in, want := range tests {
+		if got := dateYear(in); got != want {
+			t.Errorf("dateYear(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
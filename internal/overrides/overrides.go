@@ -0,0 +1,219 @@
+// Package overrides pins fields of looked up songs by hand. Overrides are
+// keyed by Spotify track ID and survive reruns of lookup, unlike edits to
+// the summary file itself.
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+)
+
+// DefaultPath is where lookup and generate look for overrides.
+const DefaultPath = "overrides.json"
+
+// YearSource marks years pinned by an override.
+const YearSource = "override"
+
+// Override pins fields of one song. Unset fields keep the looked up value.
+type Override struct {
+	// Note says why the override exists; it is not applied.
+	Note     string   `json:"note,omitempty"`
+	Title    *string  `json:"title,omitempty"`
+	Artists  []string `json:"artists,omitempty"`
+	Year     *int     `json:"year,omitempty"`
+	Genre    *string  `json:"genre,omitempty"`
+	Explicit *bool    `json:"explicit,omitempty"`
+	Invalid  *bool    `json:"invalid,omitempty"`
+	// Links maps platform keys to link URLs; an empty URL removes the link.
+	Links map[string]string `json:"links,omitempty"`
+	// Exclude drops the song from the deck.
+	Exclude bool `json:"exclude,omitempty"`
+}
+
+// Set holds overrides keyed by Spotify track ID.
+type Set map[string]Override
+
+// Load reads an overrides file. A missing file is an empty set.
+func Load(path string) (Set, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Set{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Set
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for id, o := range s {
+		for key := range o.Links {
+			if _, ok := platform.Lookup(key); !ok {
+				return nil, fmt.Errorf("%s: override for %s: %w %q", path, id, platform.ErrUnknownPlatform, key)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Apply applies the song's override, if any, and records the outcome in r.
+// Applying an override again, e.g. in generate after lookup, is a no-op
+// that still counts as applied.
+func (s Set) Apply(song *models.GeneratedSong, r *Report) {
+	spotify, _ := platform.Lookup(models.PlatformSpotify)
+	id := spotify.ParseID(song.Spotify)
+	o, ok := s[id]
+	if !ok {
+		return
+	}
+
+	a := applier{song: song, entry: r.entry(id, song.Title)}
+	if o.Title != nil {
+		pin(a, "title", &song.Title, *o.Title)
+	}
+	if o.Artists != nil {
+		if !slices.Equal(song.Artists, o.Artists) {
+			song.Artists = o.Artists
+			a.changed("artists")
+		} else {
+			a.unchanged("artists")
+		}
+	}
+	if o.Year != nil {
+		if pin(a, "year", &song.Year, *o.Year) {
+			song.YearSource = YearSource
+		}
+	}
+	if o.Genre != nil {
+		pin(a, "genre", &song.Genre, *o.Genre)
+	}
+	if o.Explicit != nil {
+		pin(a, "explicit", &song.Explicit, *o.Explicit)
+	}
+	if o.Invalid != nil {
+		pin(a, "invalid", &song.Invalid, *o.Invalid)
+	}
+
+	keys := make([]string, 0, len(o.Links))
+	for key := range o.Links {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		link := song.Link(key)
+		if pin(a, "links."+key, &link, o.Links[key]) {
+			song.SetLink(key, link)
+			status := models.PlatformStatus{Status: models.StatusOK, Method: models.MethodOverride, Score: 1}
+			if link == "" {
+				status = models.PlatformStatus{Status: models.StatusFailed, Method: models.MethodOverride, Reason: "removed by override"}
+			}
+			song.SetStatus(key, status)
+		}
+	}
+
+	if o.Exclude {
+		pin(a, "exclude", &song.Excluded, true)
+	}
+}
+
+type applier struct {
+	song  *models.GeneratedSong
+	entry *Entry
+}
+
+// changed records a field the override changed, and marks it on the song
+// so that later runs know the value is not upstream's.
+func (a applier) changed(name string) {
+	a.entry.Applied = append(a.entry.Applied, name)
+	if !slices.Contains(a.song.Overrides, name) {
+		a.song.Overrides = append(a.song.Overrides, name)
+	}
+}
+
+// unchanged records a field that already held the pinned value: stale if
+// upstream provided it, applied if an earlier run pinned it.
+func (a applier) unchanged(name string) {
+	if slices.Contains(a.song.Overrides, name) {
+		a.entry.Applied = append(a.entry.Applied, name)
+		return
+	}
+	a.entry.Stale = append(a.entry.Stale, name)
+}
+
+// pin sets *field to want and reports whether it changed.
+func pin[T comparable](a applier, name string, field *T, want T) bool {
+	if *field == want {
+		a.unchanged(name)
+		return false
+	}
+	*field = want
+	a.changed(name)
+	return true
+}
+
+// Report collects which overrides were applied during a run.
+type Report struct {
+	entries map[string]*Entry
+}
+
+// Entry is the outcome of one song's override. Stale fields already held
+// the pinned value upstream, so their override can likely be removed.
+type Entry struct {
+	Title   string
+	Applied []string
+	Stale   []string
+}
+
+func (r *Report) entry(id, title string) *Entry {
+	if r.entries == nil {
+		r.entries = make(map[string]*Entry)
+	}
+	e, ok := r.entries[id]
+	if !ok {
+		e = &Entry{Title: title}
+		r.entries[id] = e
+	}
+	return e
+}
+
+// Write prints the report. Overrides for songs the run never saw are listed
+// as unused.
+func (r *Report) Write(w io.Writer, s Set) {
+	if len(s) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(s))
+	for id := range s {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	applied, stale, unused := 0, 0, 0
+	for _, id := range ids {
+		e, ok := r.entries[id]
+		if !ok {
+			unused++
+			fmt.Fprintf(w, "  unused   %s (song not in this run)\n", id)
+			continue
+		}
+		if len(e.Applied) > 0 {
+			applied++
+			fmt.Fprintf(w, "  applied  %s %q: %v\n", id, e.Title, e.Applied)
+		}
+		if len(e.Stale) > 0 {
+			stale++
+			fmt.Fprintf(w, "  stale    %s %q: %v already match upstream\n", id, e.Title, e.Stale)
+		}
+	}
+	fmt.Fprintf(w, "Overrides: %d applied, %d stale, %d unused of %d\n", applied, stale, unused, len(s))
+}
@@ -0,0 +1,149 @@
+package overrides
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if s, err := Load(filepath.Join(dir, "missing.json")); err != nil || len(s) != 0 {
+		t.Errorf("Load of a missing file = %v, %v, want an empty set", s, err)
+	}
+
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	s, err := Load(write("ok.json", `{
+		"2WfaOiMkCvy7F5fcp2zZ8L": {"note": "single, not the album", "year": 1985, "links": {"tidal": ""}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := s["2WfaOiMkCvy7F5fcp2zZ8L"]
+	if o.Year == nil || *o.Year != 1985 || o.Title != nil {
+		t.Errorf("loaded %+v, want only the year pinned", o)
+	}
+	if link, ok := o.Links[models.PlatformTidal]; !ok || link != "" {
+		t.Errorf("loaded links %v, want the Tidal link removed", o.Links)
+	}
+
+	_, err = Load(write("unknown.json", `{"2WfaOiMkCvy7F5fcp2zZ8L": {"links": {"napster": "x"}}}`))
+	if !errors.Is(err, platform.ErrUnknownPlatform) {
+		t.Errorf("Load with an unknown platform = %v, want %v", err, platform.ErrUnknownPlatform)
+	}
+	if _, err := Load(write("bad.json", `[]`)); err == nil {
+		t.Error("Load accepted a JSON array")
+	}
+}
+
+func TestApply(t *testing.T) {
+	title, year := "Take On Me", 1985
+	s := Set{
+		"2WfaOiMkCvy7F5fcp2zZ8L": {
+			Title: &title,
+			Year:  &year,
+			Links: map[string]string{
+				models.PlatformDeezer:     "https://www.deezer.com/track/664107",
+				models.PlatformAppleMusic: "",
+			},
+			Exclude: true,
+		},
+	}
+	song := models.GeneratedSong{
+		Title:      "Take On Me",
+		Year:       2016,
+		Genre:      "pop",
+		Spotify:    "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+		AppleMusic: "https://music.apple.com/us/album/_/1?i=2",
+	}
+
+	r := &Report{}
+	s.Apply(&song, r)
+	if song.Year != 1985 || song.YearSource != YearSource {
+		t.Errorf("year = %d from %q, want 1985 from %q", song.Year, song.YearSource, YearSource)
+	}
+	if song.Genre != "pop" {
+		t.Errorf("unpinned genre changed to %q", song.Genre)
+	}
+	if song.AppleMusic != "" || song.Platforms[models.PlatformAppleMusic].Status != models.StatusFailed {
+		t.Errorf("removed Apple Music link = %q, %+v", song.AppleMusic, song.Platforms[models.PlatformAppleMusic])
+	}
+	if p := song.Platforms[models.PlatformDeezer]; song.Deezer == "" || p.Status != models.StatusOK || p.Method != models.MethodOverride {
+		t.Errorf("pinned Deezer link = %q, %+v", song.Deezer, p)
+	}
+	if !song.Excluded {
+		t.Error("song not excluded")
+	}
+	want := []string{"year", "links.appleMusic", "links.deezer", "exclude"}
+	if !slices.Equal(song.Overrides, want) {
+		t.Errorf("Overrides = %v, want %v", song.Overrides, want)
+	}
+	e := r.entries["2WfaOiMkCvy7F5fcp2zZ8L"]
+	if !slices.Equal(e.Applied, want) || !slices.Equal(e.Stale, []string{"title"}) {
+		t.Errorf("report entry = %+v, want %v applied and the title stale", e, want)
+	}
+
+	// Applying again, as generate does after lookup, changes nothing and
+	// still counts the pinned fields as applied.
+	before := song
+	r = &Report{}
+	s.Apply(&song, r)
+	if !slices.Equal(song.Overrides, before.Overrides) || song.Year != before.Year || song.Deezer != before.Deezer {
+		t.Errorf("second Apply changed the song to %+v", song)
+	}
+	if e := r.entries["2WfaOiMkCvy7F5fcp2zZ8L"]; !slices.Equal(e.Applied, want) {
+		t.Errorf("second Apply reported %v applied, want %v", e.Applied, want)
+	}
+
+	other := models.GeneratedSong{Title: "Money for Nothing", Spotify: "https://open.spotify.com/track/4yQw7FR9lcvL6RHtegbJBh"}
+	s.Apply(&other, r)
+	if other.Overrides != nil || len(r.entries) != 1 {
+		t.Errorf("Apply changed a song without an override: %+v", other)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	genre := "rock"
+	s := Set{
+		"2WfaOiMkCvy7F5fcp2zZ8L": {Genre: &genre},
+		"3fH4KjXFYMmljxrcGrbPj9": {Genre: &genre},
+		"4yQw7FR9lcvL6RHtegbJBh": {Genre: &genre},
+	}
+	r := &Report{}
+	s.Apply(&models.GeneratedSong{Title: "Take On Me", Genre: "pop", Spotify: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L"}, r)
+	s.Apply(&models.GeneratedSong{Title: "Don't You (Forget About Me)", Genre: "rock", Spotify: "https://open.spotify.com/track/3fH4KjXFYMmljxrcGrbPj9"}, r)
+
+	var buf bytes.Buffer
+	r.Write(&buf, s)
+	out := buf.String()
+	for _, want := range []string{
+		`applied  2WfaOiMkCvy7F5fcp2zZ8L "Take On Me": [genre]`,
+		`stale    3fH4KjXFYMmljxrcGrbPj9 "Don't You (Forget About Me)": [genre] already match upstream`,
+		`unused   4yQw7FR9lcvL6RHtegbJBh`,
+		"Overrides: 1 applied, 1 stale, 1 unused of 3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	r.Write(&buf, Set{})
+	if buf.Len() != 0 {
+		t.Errorf("report without overrides = %q, want nothing", buf.String())
+	}
+}
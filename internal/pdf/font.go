@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Font is a TrueType font embedded in the document as a CID-keyed font with
+// Identity-H encoding, so text can use any character the font has a glyph
+// for. Glyph widths and the ToUnicode map used for copying and searching
+// text are written by Close, once every glyph drawn is known.
+type Font struct {
+	id         int
+	name       string
+	descriptor int
+	ttf        *truetype.Font
+	// used maps the glyph indexes drawn to the characters they stand for.
+	used map[truetype.Index]rune
+}
+
+// glyph space units per em in font dictionaries.
+const em = 1000
+
+// EmbedFont writes the TrueType font data to the document.
+func (w *Writer) EmbedFont(data []byte) (*Font, error) {
+	ttf, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("()<>[]{}/%#", r) {
+			return -1
+		}
+		return r
+	}, ttf.Name(truetype.NameIDPostscriptName))
+	if name == "" {
+		name = "Embedded"
+	}
+	f := &Font{name: name, ttf: ttf, used: make(map[truetype.Index]rune)}
+
+	b := ttf.Bounds(fixed.I(em))
+	file := w.stream(fmt.Sprintf("/Length1 %d", len(data)), data)
+	f.descriptor = w.alloc()
+	w.object(f.descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, b.Min.X.Round(), b.Min.Y.Round(), b.Max.X.Round(), b.Max.Y.Round(), b.Max.Y.Round(), b.Min.Y.Round(), b.Max.Y.Round(), file))
+	f.id = w.alloc()
+	w.fonts = append(w.fonts, f)
+	return f, nil
+}
+
+// glyphs returns the glyph indexes drawing s, or an error naming the first
+// character the font has no glyph for.
+func (f *Font) glyphs(s string) ([]truetype.Index, error) {
+	glyphs := make([]truetype.Index, 0, len(s))
+	for _, r := range s {
+		g := f.ttf.Index(r)
+		if g == 0 {
+			return nil, fmt.Errorf("font %s has no glyph for %q in %q", f.name, r, s)
+		}
+		if _, ok := f.used[g]; !ok {
+			f.used[g] = r
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs, nil
+}
+
+// kerned returns the TJ operands drawing the glyphs with the font's kerning.
+// With Identity-H encoding each glyph is its two byte index.
+func (f *Font) kerned(glyphs []truetype.Index) string {
+	var ops []string
+	var run strings.Builder
+	for i, g := range glyphs {
+		if i > 0 {
+			if k := f.ttf.Kern(fixed.I(em), glyphs[i-1], g).Round(); k != 0 {
+				ops = append(ops, "<"+run.String()+">", fmt.Sprint(-k))
+				run.Reset()
+			}
+		}
+		fmt.Fprintf(&run, "%04X", uint16(g))
+	}
+	return strings.Join(append(ops, "<"+run.String()+">"), " ")
+}
+
+// writeFont writes the font dictionaries referring to the glyphs drawn.
+func (w *Writer) writeFont(f *Font) {
+	glyphs := f.usedGlyphs()
+
+	var widths strings.Builder
+	for _, g := range glyphs {
+		fmt.Fprintf(&widths, " %d [%d]", g, f.ttf.HMetric(fixed.I(em), g).AdvanceWidth.Round())
+	}
+	dw := f.ttf.HMetric(fixed.I(em), 0).AdvanceWidth.Round()
+	cid := w.alloc()
+	w.object(cid, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /DW %d /W [%s ] /CIDToGIDMap /Identity >>",
+		f.name, f.descriptor, dw, widths.String()))
+
+	toUnicode := w.stream("", toUnicodeCMap(glyphs, f.used))
+	w.object(f.id, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cid, toUnicode))
+}
+
+// usedGlyphs returns the glyph indexes drawn, in order.
+func (f *Font) usedGlyphs() []truetype.Index {
+	glyphs := make([]truetype.Index, 0, len(f.used))
+	for g := range f.used {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// toUnicodeCMap maps each glyph to its character, so viewers can copy and
+// search the text.
+func toUnicodeCMap(glyphs []truetype.Index, runes map[truetype.Index]rune) []byte {
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// At most 100 entries are allowed per block.
+	for len(glyphs) > 0 {
+		n := min(len(glyphs), 100)
+		fmt.Fprintf(&b, "%d beginbfchar\n", n)
+		for _, g := range glyphs[:n] {
+			fmt.Fprintf(&b, "<%04X> <", uint16(g))
+			for _, u := range utf16.Encode([]rune{runes[g]}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+		glyphs = glyphs[n:]
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(b.String())
+}
+
+// winAnsi encodes s as WinAnsi (Windows-1252) for the built in Helvetica,
+// replacing characters outside it with "?".
+func winAnsi(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		c, ok := winAnsiCodes[r]
+		if !ok {
+			c = '?'
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// winAnsiRunes maps codes to runes. Codes 0xa0 up match Latin-1; 0x80 to
+// 0x9f hold typographic characters.
+var winAnsiRunes [256]rune
+
+var winAnsiCodes = make(map[rune]byte)
+
+func init() {
+	high := []rune("€\x81‚ƒ„…†‡ˆ‰Š‹Œ\x8dŽ\x8f\x90‘’“”•–—˜™š›œ\x9džŸ")
+	for c := range winAnsiRunes {
+		r := rune(c)
+		if c >= 0x80 && c < 0xa0 {
+			r = high[c-0x80]
+		}
+		winAnsiRunes[c] = r
+		if c >= ' ' && c != 0x7f && !(c >= 0x80 && c < 0xa0 && r < 0xa0) {
+			winAnsiCodes[r] = byte(c)
+		}
+	}
+}
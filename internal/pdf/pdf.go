@@ -0,0 +1,301 @@
+// Package pdf writes simple PDF documents: pages of images, lines, filled
+// paths and text in Helvetica or embedded TrueType fonts. Objects are
+// written as soon as they are added, so a document of many full-page images
+// never has to fit in memory.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// PointsPerInch converts inches to PDF units.
+const PointsPerInch = 72
+
+// Rect is a rectangle in points with its origin at the bottom left of the
+// page, as in PDF itself.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Fixed object numbers; the page tree is written last, once every page is
+// known.
+const (
+	catalogObj = 1
+	pagesObj   = 2
+	fontObj    = 3
+)
+
+// Writer writes a PDF document to an underlying writer.
+type Writer struct {
+	w       *bufio.Writer
+	offset  int64
+	offsets map[int]int64
+	nextObj int
+	pages   []int
+	page    *Page
+	fonts   []*Font
+	err     error
+}
+
+// NewWriter starts a document on w. Close must be called to finish it.
+func NewWriter(w io.Writer) *Writer {
+	pw := &Writer{w: bufio.NewWriter(w), offsets: make(map[int]int64), nextObj: fontObj + 1}
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	pw.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+	pw.object(fontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	return pw
+}
+
+// Page is the page being drawn. It is finished by the next NewPage or by
+// Close.
+type Page struct {
+	w       *Writer
+	width   float64
+	height  float64
+	content bytes.Buffer
+	images  []int
+	fonts   []int
+}
+
+// NewPage finishes the current page and starts a new one of the given size
+// in points.
+func (w *Writer) NewPage(width, height float64) *Page {
+	w.endPage()
+	w.page = &Page{w: w, width: width, height: height}
+	return w.page
+}
+
+// Image draws img scaled to dst, clipped to clip.
+func (p *Page) Image(img image.Image, dst, clip Rect) {
+	p.Push()
+	fmt.Fprintf(&p.content, "%s %s %s %s re W n\n", num(clip.X), num(clip.Y), num(clip.W), num(clip.H))
+	p.DrawImage(img, dst)
+	p.Pop()
+}
+
+// DrawImage draws img scaled to dst, within the current clip.
+func (p *Page) DrawImage(img image.Image, dst Rect) {
+	id := p.w.image(img)
+	p.images = append(p.images, id)
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n", num(dst.W), num(dst.H), num(dst.X), num(dst.Y), id)
+}
+
+// Push saves the graphics state: colour, transform and clip.
+func (p *Page) Push() {
+	p.content.WriteString("q\n")
+}
+
+// Pop restores the state saved by the matching Push.
+func (p *Page) Pop() {
+	p.content.WriteString("Q\n")
+}
+
+// Transform concatenates the matrix [a b c d e f] to the transform.
+func (p *Page) Transform(m Matrix) {
+	fmt.Fprintf(&p.content, "%s cm\n", m)
+}
+
+// SetColor sets the fill colour, ignoring alpha.
+func (p *Page) SetColor(c color.Color) {
+	r, g, b, _ := c.RGBA()
+	fmt.Fprintf(&p.content, "%s %s %s rg\n", num(float64(r)/0xffff), num(float64(g)/0xffff), num(float64(b)/0xffff))
+}
+
+// RoundedRect adds r with corners of the given radius to the current path.
+func (p *Page) RoundedRect(r Rect, radius float64) {
+	radius = min(radius, r.W/2, r.H/2)
+	if radius <= 0 {
+		fmt.Fprintf(&p.content, "%s %s %s %s re\n", num(r.X), num(r.Y), num(r.W), num(r.H))
+		return
+	}
+	// Bezier control points approximating a quarter circle.
+	k := radius * (1 - 0.5523)
+	x0, y0, x1, y1 := r.X, r.Y, r.X+r.W, r.Y+r.H
+	fmt.Fprintf(&p.content, "%s %s m\n", num(x0+radius), num(y0))
+	fmt.Fprintf(&p.content, "%s %s l %s %s %s %s %s %s c\n", num(x1-radius), num(y0), num(x1-k), num(y0), num(x1), num(y0+k), num(x1), num(y0+radius))
+	fmt.Fprintf(&p.content, "%s %s l %s %s %s %s %s %s c\n", num(x1), num(y1-radius), num(x1), num(y1-k), num(x1-k), num(y1), num(x1-radius), num(y1))
+	fmt.Fprintf(&p.content, "%s %s l %s %s %s %s %s %s c\n", num(x0+radius), num(y1), num(x0+k), num(y1), num(x0), num(y1-k), num(x0), num(y1-radius))
+	fmt.Fprintf(&p.content, "%s %s l %s %s %s %s %s %s c h\n", num(x0), num(y0+radius), num(x0), num(y0+k), num(x0+k), num(y0), num(x0+radius), num(y0))
+}
+
+// Fill fills the current path with the fill colour.
+func (p *Page) Fill() {
+	p.content.WriteString("f\n")
+}
+
+// Clip intersects the clip with the current path.
+func (p *Page) Clip() {
+	p.content.WriteString("W n\n")
+}
+
+// Line strokes a black line width points wide.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect strokes the outline of r.
+func (p *Page) Rect(r Rect, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n", num(width), num(r.X), num(r.Y), num(r.W), num(r.H))
+}
+
+// Text draws s in black Helvetica with its baseline starting at x, y.
+func (p *Page) Text(x, y, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT 0 g /F%d %s Tf %s %s Td (%s) Tj ET\n", fontObj, num(size), num(x), num(y), escape(winAnsi(s)))
+}
+
+// DrawText draws s in an embedded font and the fill colour, with the text
+// space mapped by m; Matrix{1, 0, 0, 1, x, y} starts the baseline at x, y.
+// It draws nothing if the font lacks a glyph for any character of s.
+func (p *Page) DrawText(f *Font, size float64, m Matrix, s string) error {
+	glyphs, err := f.glyphs(s)
+	if err != nil {
+		return err
+	}
+	p.fonts = append(p.fonts, f.id)
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s Tm [%s] TJ ET\n", f.id, num(size), m, f.kerned(glyphs))
+	return nil
+}
+
+// Close finishes the last page and the document. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	w.endPage()
+	for _, f := range w.fonts {
+		w.writeFont(f)
+	}
+
+	kids := make([]string, len(w.pages))
+	for i, id := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	w.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+
+	xref := w.offset
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", w.nextObj)
+	for id := 1; id < w.nextObj; id++ {
+		w.printf("%010d 00000 n \n", w.offsets[id])
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", w.nextObj, catalogObj, xref)
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) endPage() {
+	p := w.page
+	if p == nil {
+		return
+	}
+	w.page = nil
+
+	contents := w.stream("", p.content.Bytes())
+	var xobjects, fonts strings.Builder
+	for _, id := range p.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", id, id)
+	}
+	seen := map[int]bool{}
+	for _, id := range append([]int{fontObj}, p.fonts...) {
+		if !seen[id] {
+			seen[id] = true
+			fmt.Fprintf(&fonts, " /F%d %d 0 R", id, id)
+		}
+	}
+	id := w.alloc()
+	w.object(id, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font <<%s >> /XObject <<%s >> >> /Contents %d 0 R >>",
+		pagesObj, num(p.width), num(p.height), fonts.String(), xobjects.String(), contents))
+	w.pages = append(w.pages, id)
+}
+
+// image writes img as an RGB image XObject, with a soft mask if it is not
+// opaque, and returns its object number.
+func (w *Writer) image(img image.Image) int {
+	b := img.Bounds()
+	pix := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// PDF images are not premultiplied.
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pix = append(pix, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	if opaque {
+		return w.stream(dict+" /ColorSpace /DeviceRGB", pix)
+	}
+	mask := w.stream(dict+" /ColorSpace /DeviceGray", alpha)
+	return w.stream(fmt.Sprintf("%s /ColorSpace /DeviceRGB /SMask %d 0 R", dict, mask), pix)
+}
+
+// stream writes data deflated as a stream object with the extra dictionary
+// entries and returns its object number.
+func (w *Writer) stream(dict string, data []byte) int {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+
+	id := w.alloc()
+	w.offsets[id] = w.offset
+	w.printf("%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", id, strings.TrimSpace(dict), buf.Len())
+	w.write(buf.Bytes())
+	w.printf("\nendstream\nendobj\n")
+	return id
+}
+
+func (w *Writer) alloc() int {
+	w.nextObj++
+	return w.nextObj - 1
+}
+
+func (w *Writer) object(id int, body string) {
+	w.offsets[id] = w.offset
+	w.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	w.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	w.err = err
+}
+
+// Matrix is a PDF transformation matrix [a b c d e f].
+type Matrix [6]float64
+
+func (m Matrix) String() string {
+	s := make([]string, len(m))
+	for i, f := range m {
+		s[i] = num(f)
+	}
+	return strings.Join(s, " ")
+}
+
+func escape(b []byte) string {
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	return r.Replace(string(b))
+}
+
+// num formats a coordinate without exponent notation, which PDF does not
+// allow.
+func num(f float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.3f", f), "0")
+	return strings.TrimSuffix(s, ".")
+}
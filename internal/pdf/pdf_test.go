@@ -0,0 +1,142 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// checkXref fails unless every object in the cross-reference table starts
+// at its recorded offset.
+func checkXref(t *testing.T, doc []byte) int {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatalf("no startxref trailer in %q", doc[max(0, len(doc)-100):])
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	lines := strings.Split(string(doc[xref:]), "\n")
+	var n int
+	fmt.Sscanf(lines[1], "0 %d", &n)
+	for id := 1; id < n; id++ {
+		offset, _ := strconv.Atoi(lines[2+id][:10])
+		if want := fmt.Sprintf("%d 0 obj\n", id); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", id, offset, doc[offset:offset+10])
+		}
+	}
+	return n
+}
+
+func TestWriter(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	p := w.NewPage(612, 792)
+	p.Image(img, Rect{X: 10, Y: 20, W: 40, H: 20}, Rect{X: 10, Y: 20, W: 30, H: 20})
+	p.Line(0, 0, 1.5, -0.0001, 0.25)
+	p.Text(72, 72, 12, "A (b)")
+	w.NewPage(595.276, 841.89)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.Bytes()
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) {
+		t.Errorf("header = %q", doc[:9])
+	}
+	checkXref(t, doc)
+	for _, want := range []string{
+		"/Count 2",
+		"/MediaBox [0 0 612 792]",
+		"/MediaBox [0 0 595.276 841.89]",
+		"/Width 4 /Height 2",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+}
+
+func TestNum(t *testing.T) {
+	for f, want := range map[float64]string{0: "0", 10: "10", 1.5: "1.5", 0.125: "0.125", 1e-7: "0", 1e21: "1000000000000000000000"} {
+		if got := num(f); got != want {
+			t.Errorf("num(%v) = %q, want %q", f, got, want)
+		}
+	}
+}
+
+func TestEmbedFont(t *testing.T) {
+	data, err := os.ReadFile("../../assets/fonts/Arial.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	f, err := w.EmbedFont(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := w.NewPage(100, 100)
+	// Outside WinAnsi: Polish, Hungarian, Turkish and Cyrillic.
+	for _, s := range []string{"Café", "Łódź Kossuth Dőlt Beşiktaş", "Кино – Группа крови"} {
+		if err := p.DrawText(f, 12, Matrix{1, 0, 0, 1, 10, 10}, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.DrawText(f, 12, Matrix{1, 0, 0, 1, 10, 10}, "東京事変"); err == nil {
+		t.Error("DrawText accepted characters the font has no glyphs for")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.Bytes()
+	checkXref(t, doc)
+	for _, want := range []string{
+		"/Subtype /Type0",
+		"/Encoding /Identity-H",
+		"/Subtype /CIDFontType2",
+		"/CIDToGIDMap /Identity",
+		"/ToUnicode",
+		fmt.Sprintf("/Length1 %d", len(data)),
+		fmt.Sprintf("/F%d %d 0 R", f.id, f.id),
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+
+	// Each character is drawn as its glyph and mapped back to itself.
+	cmap := string(toUnicodeCMap(f.usedGlyphs(), f.used))
+	for _, r := range "ŁőşКиГ" {
+		g := f.ttf.Index(r)
+		if want := fmt.Sprintf("<%04X> <%04X>\n", g, r); !strings.Contains(cmap, want) {
+			t.Errorf("ToUnicode has no %q for %q", want, r)
+		}
+		if !strings.Contains(f.kerned([]truetype.Index{g}), fmt.Sprintf("%04X", g)) {
+			t.Errorf("%q is not drawn as glyph %d", r, g)
+		}
+	}
+
+	if _, err := w.EmbedFont([]byte("not a font")); err == nil {
+		t.Error("EmbedFont accepted garbage")
+	}
+}
+
+func TestWinAnsi(t *testing.T) {
+	if got, want := winAnsi("Café – €5 ☃"), []byte("Caf\xe9 \x96 \x805 ?"); !bytes.Equal(got, want) {
+		t.Errorf("winAnsi = %q, want %q", got, want)
+	}
+}
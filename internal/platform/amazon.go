@@ -0,0 +1,147 @@
+package platform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+	xhtml "golang.org/x/net/html"
+)
+
+func init() { Register(func(c Config) Platform { return amazonMusic{c} }) }
+
+// amazonMusic IDs are "<AlbumASIN>:<TrackASIN>".
+type amazonMusic struct{ Config }
+
+func (amazonMusic) Key() string  { return models.PlatformAmazonMusic }
+func (amazonMusic) Name() string { return "Amazon Music" }
+
+func (amazonMusic) ParseID(link string) string {
+	// Expected: https://music.amazon.com/albums/<AlbumASIN>?trackAsin=<TrackASIN>
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	trackASIN := u.Query().Get("trackAsin")
+
+	// Extract Album ASIN from path
+	parts := strings.Split(u.Path, "/")
+	albumASIN := parts[len(parts)-1]
+	if albumASIN != "" && trackASIN != "" {
+		return fmt.Sprintf("%s:%s", albumASIN, trackASIN)
+	}
+	return ""
+}
+
+func (amazonMusic) URL(id string) string {
+	album, track, ok := strings.Cut(id, ":")
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("https://music.amazon.com/albums/%s?trackAsin=%s", album, track)
+}
+
+// Validate scores the embed page; Amazon pages carry no ISRC.
+func (a amazonMusic) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	_, track, ok := strings.Cut(id, ":")
+	if !ok || track == "" {
+		return models.Match{}, fmt.Errorf("%w: malformed amazon music id %q", ErrMismatch, id)
+	}
+	return a.matchSong(song, "", func() (float64, error) {
+		return pageScore(client, a.Upstream.AmazonMusic+"/embed/"+track, song)
+	})
+}
+
+func (a amazonMusic) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	asins, err := a.search(client, song.Title, song.Artists[0])
+	if err != nil {
+		return "", models.Match{}, err
+	}
+
+	// Search results are tracks, so the track ASIN doubles as the album.
+	var ids []string
+	for _, asin := range asins[:min(len(asins), maxCandidates)] {
+		ids = append(ids, fmt.Sprintf("%s:%s", asin, asin))
+	}
+	return bestCandidate(ids, func(id string) (models.Match, error) {
+		return a.Validate(client, id, song)
+	})
+}
+
+func (amazonMusic) Encode(id string, p *codec.CardPayload) error {
+	album, track, ok := strings.Cut(id, ":")
+	if !ok {
+		return fmt.Errorf("malformed amazon music id %q", id)
+	}
+	p.AmazonAlbum = album
+	p.AmazonTrack = track
+	return nil
+}
+
+func (amazonMusic) Decode(p codec.CardPayload) string {
+	if p.AmazonTrack == "" {
+		return ""
+	}
+	return p.AmazonAlbum + ":" + p.AmazonTrack
+}
+
+// search returns the ASINs of the tracks a store search finds.
+func (a amazonMusic) search(client *retryablehttp.Client, title, artist string) ([]string, error) {
+	term := fmt.Sprintf("%s %s", title, artist)
+	u, _ := url.Parse(a.Upstream.Amazon + "/s")
+	q := u.Query()
+	q.Set("k", term)
+	q.Set("i", "digital-music")
+	u.RawQuery = q.Encode()
+
+	req, _ := retryablehttp.NewRequest("GET", u.String(), nil)
+	req.Header.Set("User-Agent", browserUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	doc, err := xhtml.Parse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var asins []string
+	var f func(*xhtml.Node)
+	f = func(n *xhtml.Node) {
+		if n.Type == xhtml.ElementNode && n.Data == "div" {
+			isSearchResult := false
+			asin := ""
+			for _, a := range n.Attr {
+				if a.Key == "data-component-type" && a.Val == "s-search-result" {
+					isSearchResult = true
+				}
+				if a.Key == "data-asin" {
+					asin = a.Val
+				}
+			}
+			if isSearchResult && asin != "" {
+				asins = append(asins, asin)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+
+	if len(asins) > 0 {
+		return asins, nil
+	}
+	return nil, ErrNoResults
+}
@@ -0,0 +1,183 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+func init() { Register(func(c Config) Platform { return appleMusic{c} }) }
+
+// appleMusic IDs are "<AlbumID>:<TrackID>".
+type appleMusic struct{ Config }
+
+func (appleMusic) Key() string  { return models.PlatformAppleMusic }
+func (appleMusic) Name() string { return "Apple Music" }
+
+func (appleMusic) ParseID(link string) string {
+	// Expected: https://music.apple.com/us/album/_/<AlbumID>?i=<TrackID>
+	// Odesli links use geo.music.apple.com and add tracking parameters.
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	trackID := u.Query().Get("i")
+
+	// Extract Album ID from path
+	parts := strings.Split(u.Path, "/")
+	albumID := parts[len(parts)-1]
+	if albumID != "" && trackID != "" {
+		return fmt.Sprintf("%s:%s", albumID, trackID)
+	}
+	return ""
+}
+
+func (appleMusic) URL(id string) string {
+	album, track, ok := strings.Cut(id, ":")
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("https://music.apple.com/us/album/_/%s?i=%s", album, track)
+}
+
+func (a appleMusic) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	album, track, ok := strings.Cut(id, ":")
+	if !ok {
+		return models.Match{}, fmt.Errorf("%w: malformed apple music id %q", ErrMismatch, id)
+	}
+	link := fmt.Sprintf("%s/us/album/_/%s?i=%s", a.Upstream.AppleMusic, album, track)
+
+	isrc := ""
+	if song.ISRC != "" {
+		if t, err := a.lookup(client, track); err == nil {
+			isrc = t.ISRC
+		}
+	}
+	return a.matchSong(song, isrc, func() (float64, error) {
+		return pageScore(client, link, song)
+	})
+}
+
+// Search scores the iTunes results by their own metadata, so no pages need
+// fetching.
+func (a appleMusic) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	candidates, err := a.search(client, song.Title, song.Artists[0])
+	if err != nil {
+		return "", models.Match{}, err
+	}
+
+	tracks := make(map[string]iTunesTrack)
+	var ids []string
+	for _, c := range candidates {
+		if id := a.ParseID(c.TrackViewUrl); id != "" {
+			tracks[id] = c
+			ids = append(ids, id)
+		}
+	}
+
+	return bestCandidate(ids, func(id string) (models.Match, error) {
+		t := tracks[id]
+		return a.matchSong(song, t.ISRC, func() (float64, error) {
+			return metadataScore(song, t.TrackName, t.ArtistName), nil
+		})
+	})
+}
+
+func (appleMusic) Encode(id string, p *codec.CardPayload) error {
+	album, track, ok := strings.Cut(id, ":")
+	if !ok {
+		return fmt.Errorf("malformed apple music id %q", id)
+	}
+	p.AppleAlbum = album
+	p.AppleTrack = track
+	return nil
+}
+
+func (appleMusic) Decode(p codec.CardPayload) string {
+	if p.AppleTrack == "" {
+		return ""
+	}
+	return p.AppleAlbum + ":" + p.AppleTrack
+}
+
+type iTunesTrack struct {
+	TrackViewUrl string `json:"trackViewUrl"`
+	TrackName    string `json:"trackName"`
+	ArtistName   string `json:"artistName"`
+	ISRC         string `json:"isrc"`
+}
+
+type iTunesResponse struct {
+	Results []iTunesTrack `json:"results"`
+}
+
+func (a appleMusic) search(client *retryablehttp.Client, title, artist string) ([]iTunesTrack, error) {
+	term := fmt.Sprintf("%s %s", title, artist)
+	u, _ := url.Parse(a.Upstream.ITunes + "/search")
+	q := u.Query()
+	q.Set("term", term)
+	q.Set("country", "US")
+	q.Set("media", "music")
+	q.Set("entity", "song")
+	q.Set("limit", "5")
+	u.RawQuery = q.Encode()
+
+	result, err := getITunes(client, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []iTunesTrack
+	for _, item := range result.Results {
+		if item.TrackViewUrl != "" {
+			tracks = append(tracks, item)
+		}
+	}
+	if len(tracks) > 0 {
+		return tracks, nil
+	}
+	return nil, ErrNoResults
+}
+
+// lookup fetches a single track from the iTunes lookup API.
+func (a appleMusic) lookup(client *retryablehttp.Client, trackID string) (iTunesTrack, error) {
+	u, _ := url.Parse(a.Upstream.ITunes + "/lookup")
+	q := u.Query()
+	q.Set("id", trackID)
+	q.Set("country", "US")
+	q.Set("entity", "song")
+	u.RawQuery = q.Encode()
+
+	result, err := getITunes(client, u.String())
+	if err != nil {
+		return iTunesTrack{}, err
+	}
+	if len(result.Results) == 0 {
+		return iTunesTrack{}, ErrNoResults
+	}
+	return result.Results[0], nil
+}
+
+func getITunes(client *retryablehttp.Client, u string) (*iTunesResponse, error) {
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var result iTunesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,168 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// deezerNoData is the API error code for an unknown track or ISRC.
+const deezerNoData = 800
+
+func init() { Register(func(c Config) Platform { return deezer{c} }) }
+
+// deezer IDs are numeric track IDs.
+type deezer struct{ Config }
+
+func (deezer) Key() string  { return models.PlatformDeezer }
+func (deezer) Name() string { return "Deezer" }
+
+func (deezer) ParseID(link string) string {
+	// Expected: https://www.deezer.com/track/<ID>
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return trackPathID(u.Path)
+}
+
+func (deezer) URL(id string) string {
+	return "https://www.deezer.com/track/" + id
+}
+
+type deezerTrack struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	ISRC   string `json:"isrc"`
+	Artist struct {
+		Name string `json:"name"`
+	} `json:"artist"`
+	Error *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+func (d deezer) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	if !isNumericID(id) {
+		return models.Match{}, fmt.Errorf("%w: malformed deezer id %q", ErrMismatch, id)
+	}
+
+	track, err := d.track(client, id)
+	if err != nil {
+		return models.Match{}, err
+	}
+
+	return d.matchSong(song, track.ISRC, func() (float64, error) {
+		return metadataScore(song, track.Title, track.Artist.Name), nil
+	})
+}
+
+// Search looks the ISRC up directly, then scores text search results by
+// their own metadata.
+func (d deezer) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	if song.ISRC != "" {
+		track, err := d.track(client, "isrc:"+url.PathEscape(song.ISRC))
+		if err == nil && track.ID != 0 && strings.EqualFold(track.ISRC, song.ISRC) {
+			return strconv.FormatInt(track.ID, 10), models.Match{Method: MatchISRC, Score: 1}, nil
+		}
+	}
+
+	candidates, err := d.search(client, song.Title, song.Artists[0])
+	if err != nil {
+		return "", models.Match{}, err
+	}
+
+	tracks := make(map[string]deezerTrack)
+	var ids []string
+	for _, c := range candidates {
+		id := strconv.FormatInt(c.ID, 10)
+		tracks[id] = c
+		ids = append(ids, id)
+	}
+
+	// Search results carry no ISRC, so they match by text
+	return bestCandidate(ids, func(id string) (models.Match, error) {
+		t := tracks[id]
+		return d.matchSong(song, "", func() (float64, error) {
+			return metadataScore(song, t.Title, t.Artist.Name), nil
+		})
+	})
+}
+
+// track fetches a track by ID, or by "isrc:<ISRC>".
+func (d deezer) track(client *retryablehttp.Client, ref string) (*deezerTrack, error) {
+	resp, err := client.Get(fmt.Sprintf("%s/track/%s", d.Upstream.Deezer, ref))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var result deezerTrack
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		if result.Error.Code == deezerNoData {
+			return nil, fmt.Errorf("%w: deezer error: %s", ErrMismatch, result.Error.Message)
+		}
+		return nil, fmt.Errorf("deezer error: %s", result.Error.Message)
+	}
+	return &result, nil
+}
+
+func (deezer) Encode(id string, p *codec.CardPayload) error {
+	p.Deezer = id
+	return nil
+}
+
+func (deezer) Decode(p codec.CardPayload) string {
+	return p.Deezer
+}
+
+func (d deezer) search(client *retryablehttp.Client, title, artist string) ([]deezerTrack, error) {
+	u, _ := url.Parse(d.Upstream.Deezer + "/search")
+	q := u.Query()
+	q.Set("q", fmt.Sprintf("artist:%q track:%q", artist, title))
+	q.Set("limit", "5")
+	u.RawQuery = q.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []deezerTrack `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var tracks []deezerTrack
+	for _, item := range result.Data {
+		if item.ID != 0 {
+			tracks = append(tracks, item)
+		}
+	}
+	if len(tracks) > 0 {
+		return tracks, nil
+	}
+	return nil, ErrNoResults
+}
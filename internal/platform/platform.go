@@ -0,0 +1,158 @@
+// Package platform describes the streaming services a card links to: how
+// their IDs are parsed from URLs, validated, searched for and stored in the
+// QR payload.
+//
+// Lookup, fixing, policies, overrides and card generation all iterate over
+// the registry, but each platform's link is still stored in a field of its
+// own. Adding a platform therefore also needs its key in internal/models, a
+// field with its JSON name and a case in linkField on both models.Song and
+// models.GeneratedSong, a field in codec.CardPayload with a presence bit in
+// the v2 layout (and the web decoder and golden vectors to match), an
+// endpoint in internal/endpoints with a fake in internal/fakes, and cache
+// and rate limit entries for its hosts.
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/endpoints"
+	"temporalize/internal/match"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const browserUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+var (
+	ErrNoResults       = errors.New("no results")
+	ErrUnknownPlatform = errors.New("unknown platform")
+	// ErrMismatch is wrapped by errors meaning the platform answered and
+	// the ID is not the song, as opposed to the platform being unreachable.
+	ErrMismatch     = errors.New("not the song")
+	ErrISRCMismatch = fmt.Errorf("%w: isrc mismatch", ErrMismatch)
+)
+
+// Mismatch reports whether err from Validate means the ID is wrong or dead,
+// rather than that it could not be checked.
+func Mismatch(err error) bool {
+	return errors.Is(err, ErrMismatch) || errors.Is(err, ErrNoResults)
+}
+
+// How a platform ID was matched to the song.
+const (
+	// MatchSource marks the platform the song was collected from.
+	MatchSource = "source"
+	// MatchISRC means the platform reported the song's ISRC.
+	MatchISRC = "isrc"
+	// MatchText means the title and artist scored at least the
+	// configured MatchThreshold,
+	// because the song or the platform had no ISRC.
+	MatchText = "text"
+)
+
+// Platform is a streaming service a card can link to. IDs are the compact
+// form stored on models.Song, e.g. "<album>:<track>" for Apple Music.
+type Platform interface {
+	// Key identifies the platform in Odesli responses, models and flags.
+	Key() string
+	// Name is the human readable name used in log output.
+	Name() string
+	// ParseID extracts the compact ID from a platform URL, returning "" if
+	// the URL is not recognised.
+	ParseID(link string) string
+	// URL returns the canonical listening URL for an ID.
+	URL(id string) string
+	// Validate checks that the ID points at the song and returns how it
+	// matched, preferring the ISRC over title and artist scoring.
+	Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error)
+	// Search looks the song up on the platform and returns the ID of the
+	// best matching candidate, and how it matched.
+	Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error)
+	// Encode stores the ID in the QR payload.
+	Encode(id string, p *codec.CardPayload) error
+	// Decode returns the ID stored in the payload, or "" if there is none.
+	Decode(p codec.CardPayload) string
+}
+
+// Config is what the platforms need to validate and search: the services
+// they query and how closely text must match.
+type Config struct {
+	// Upstream holds the service roots; tests point them at fakes.
+	Upstream endpoints.Endpoints
+	// MatchThreshold is the lowest text similarity score accepted as a
+	// match.
+	MatchThreshold float64
+}
+
+// DefaultConfig queries the real services with the default threshold.
+func DefaultConfig() Config {
+	return Config{Upstream: endpoints.Default, MatchThreshold: match.DefaultThreshold}
+}
+
+var (
+	builders []func(Config) Platform
+	// registry holds every platform built with DefaultConfig.
+	registry []Platform
+)
+
+// Register adds a platform, built from a Config by build, to the registry.
+// It is called from init.
+func Register(build func(Config) Platform) {
+	p := build(DefaultConfig())
+	for _, existing := range registry {
+		if existing.Key() == p.Key() {
+			panic(fmt.Sprintf("platform %q registered twice", p.Key()))
+		}
+	}
+	builders = append(builders, build)
+	registry = append(registry, p)
+}
+
+// All returns every registered platform built with DefaultConfig. Their
+// IDs, URLs and payload fields do not depend on the Config; use Select to
+// validate and search with another.
+func All() []Platform {
+	return append([]Platform(nil), registry...)
+}
+
+// Lookup returns the platform registered under key.
+func Lookup(key string) (Platform, bool) {
+	for _, p := range registry {
+		if p.Key() == key {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of every registered platform.
+func Keys() []string {
+	keys := make([]string, len(registry))
+	for i, p := range registry {
+		keys[i] = p.Key()
+	}
+	return keys
+}
+
+// Select parses a comma separated list of platform keys and builds the
+// platforms it names with cfg. An empty list selects every platform.
+func Select(list string, cfg Config) ([]Platform, error) {
+	if strings.TrimSpace(list) == "" {
+		list = strings.Join(Keys(), ",")
+	}
+	var selected []Platform
+	for _, key := range strings.Split(list, ",") {
+		key = strings.TrimSpace(key)
+		i := slices.IndexFunc(registry, func(p Platform) bool { return p.Key() == key })
+		if i < 0 {
+			return nil, fmt.Errorf("%w %q (known: %s)", ErrUnknownPlatform, key, strings.Join(Keys(), ", "))
+		}
+		selected = append(selected, builders[i](cfg))
+	}
+	return selected, nil
+}
@@ -0,0 +1,197 @@
+package platform
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		links []string
+		id    string
+		url   string
+	}{
+		models.PlatformSpotify: {
+			links: []string{"https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L?si=abc", "spotify:track:2WfaOiMkCvy7F5fcp2zZ8L", "2WfaOiMkCvy7F5fcp2zZ8L"},
+			id:    "2WfaOiMkCvy7F5fcp2zZ8L",
+			url:   "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L",
+		},
+		models.PlatformAppleMusic: {
+			links: []string{"https://geo.music.apple.com/us/album/take-on-me/1035048414?i=1035048822&uo=4&app=music", "https://music.apple.com/us/album/_/1035048414?i=1035048822"},
+			id:    "1035048414:1035048822",
+			url:   "https://music.apple.com/us/album/_/1035048414?i=1035048822",
+		},
+		models.PlatformAmazonMusic: {
+			links: []string{"https://music.amazon.com/albums/B00FYLCW6K?trackAsin=B00FYLCVZI&do=play"},
+			id:    "B00FYLCW6K:B00FYLCVZI",
+			url:   "https://music.amazon.com/albums/B00FYLCW6K?trackAsin=B00FYLCVZI",
+		},
+		models.PlatformYoutubeMusic: {
+			links: []string{"https://music.youtube.com/watch?v=djV11Xbc914&feature=share"},
+			id:    "djV11Xbc914",
+			url:   "https://music.youtube.com/watch?v=djV11Xbc914",
+		},
+		models.PlatformDeezer: {
+			links: []string{"https://www.deezer.com/track/664107", "https://www.deezer.com/en/track/664107/"},
+			id:    "664107",
+			url:   "https://www.deezer.com/track/664107",
+		},
+		models.PlatformTidal: {
+			links: []string{"https://listen.tidal.com/track/1309291", "https://tidal.com/browse/track/1309291"},
+			id:    "1309291",
+			url:   "https://tidal.com/browse/track/1309291",
+		},
+	}
+
+	var payload codec.CardPayload
+	for _, p := range All() {
+		tt, ok := tests[p.Key()]
+		if !ok {
+			t.Errorf("no round trip test for %s", p.Key())
+			continue
+		}
+		for _, link := range tt.links {
+			if got := p.ParseID(link); got != tt.id {
+				t.Errorf("%s.ParseID(%q) = %q, want %q", p.Key(), link, got, tt.id)
+			}
+		}
+		if got := p.URL(tt.id); got != tt.url {
+			t.Errorf("%s.URL(%q) = %q, want %q", p.Key(), tt.id, got, tt.url)
+		}
+		if got := p.ParseID(p.URL(tt.id)); got != tt.id {
+			t.Errorf("%s.ParseID(URL(%q)) = %q", p.Key(), tt.id, got)
+		}
+		if got := p.Decode(payload); got != "" {
+			t.Errorf("%s.Decode of an empty payload = %q", p.Key(), got)
+		}
+		if err := p.Encode(tt.id, &payload); err != nil {
+			t.Errorf("%s.Encode(%q) = %v", p.Key(), tt.id, err)
+		}
+	}
+
+	// Every platform's ID survives the payload's binary encoding.
+	data, err := payload.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded codec.CardPayload
+	if err := decoded.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range All() {
+		if want := tests[p.Key()].id; p.Decode(decoded) != want {
+			t.Errorf("%s.Decode = %q, want %q", p.Key(), p.Decode(decoded), want)
+		}
+	}
+}
+
+func TestParseIDRejects(t *testing.T) {
+	for key, links := range map[string][]string{
+		models.PlatformSpotify:      {"https://open.spotify.com/album/1", "short"},
+		models.PlatformAppleMusic:   {"https://music.apple.com/us/album/_/1035048414", "::"},
+		models.PlatformAmazonMusic:  {"https://music.amazon.com/albums/B00FYLCW6K"},
+		models.PlatformYoutubeMusic: {"https://music.youtube.com/channel/UC1"},
+		models.PlatformDeezer:       {"https://www.deezer.com/album/75980"},
+		models.PlatformTidal:        {"https://tidal.com/browse/album/1309290"},
+	} {
+		p, _ := Lookup(key)
+		for _, link := range links {
+			if got := p.ParseID(link); got != "" {
+				t.Errorf("%s.ParseID(%q) = %q, want none", key, link, got)
+			}
+		}
+	}
+	for _, key := range []string{models.PlatformAppleMusic, models.PlatformAmazonMusic} {
+		p, _ := Lookup(key)
+		if err := p.Encode("no-separator", &codec.CardPayload{}); err == nil {
+			t.Errorf("%s.Encode accepted an ID without album", key)
+		}
+		if got := p.URL("no-separator"); got != "" {
+			t.Errorf("%s.URL of an ID without album = %q", key, got)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	all, err := Select("", DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, p := range all {
+		keys = append(keys, p.Key())
+	}
+	if !reflect.DeepEqual(keys, Keys()) {
+		t.Errorf("Select(\"\") = %v, want %v", keys, Keys())
+	}
+
+	cfg := Config{MatchThreshold: 0.5}
+	cfg.Upstream.Deezer = "http://deezer.test"
+	some, err := Select(" tidal, deezer ", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(some) != 2 || some[0].Key() != models.PlatformTidal || some[1].Key() != models.PlatformDeezer {
+		t.Errorf("Select = %v", some)
+	}
+	if d, ok := some[1].(deezer); !ok || d.Config != cfg {
+		t.Errorf("Select built %#v, want the given config", some[1])
+	}
+	// The registry's platforms keep the defaults.
+	if d, _ := Lookup(models.PlatformDeezer); d.(deezer).Config != DefaultConfig() {
+		t.Error("Select changed the registered platform's config")
+	}
+
+	for _, list := range []string{"deezer,napster", "Deezer", "deezer,,tidal"} {
+		_, err := Select(list, DefaultConfig())
+		if !errors.Is(err, ErrUnknownPlatform) {
+			t.Errorf("Select(%q) = %v, want ErrUnknownPlatform", list, err)
+		} else if !strings.Contains(err.Error(), "known: "+strings.Join(Keys(), ", ")) {
+			t.Errorf("Select(%q) error %q does not list the known platforms", list, err)
+		}
+	}
+}
+
+func TestBestCandidate(t *testing.T) {
+	text := func(score float64) models.Match { return models.Match{Method: MatchText, Score: score} }
+	isrc := models.Match{Method: MatchISRC, Score: 1}
+	tests := []struct {
+		name    string
+		matches map[string]models.Match
+		ids     []string
+		want    string
+		tried   []string
+	}{
+		{"highest score", map[string]models.Match{"a": text(0.8), "c": text(0.95), "d": text(0.9)}, []string{"a", "b", "c", "d"}, "c", []string{"a", "b", "c", "d"}},
+		{"first of equal scores", map[string]models.Match{"a": text(0.9), "b": text(0.9)}, []string{"a", "b"}, "a", []string{"a", "b"}},
+		{"isrc outright", map[string]models.Match{"a": text(1), "b": isrc, "c": text(1)}, []string{"a", "b", "c"}, "b", []string{"a", "b"}},
+		{"no match", map[string]models.Match{}, []string{"a", "b"}, "", []string{"a", "b"}},
+		{"no candidates", nil, nil, "", nil},
+	}
+	for _, tt := range tests {
+		var tried []string
+		id, m, err := bestCandidate(tt.ids, func(id string) (models.Match, error) {
+			tried = append(tried, id)
+			m, ok := tt.matches[id]
+			if !ok {
+				return models.Match{}, ErrLowScore
+			}
+			return m, nil
+		})
+		if tt.want == "" {
+			if !errors.Is(err, ErrNoResults) {
+				t.Errorf("%s: bestCandidate = %q, %v, want ErrNoResults", tt.name, id, err)
+			}
+		} else if err != nil || id != tt.want || m != tt.matches[tt.want] {
+			t.Errorf("%s: bestCandidate = %q, %+v, %v, want %q", tt.name, id, m, err, tt.want)
+		}
+		if !reflect.DeepEqual(tried, tt.tried) {
+			t.Errorf("%s: tried %v, want %v", tt.name, tried, tt.tried)
+		}
+	}
+}
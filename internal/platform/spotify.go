@@ -0,0 +1,70 @@
+package platform
+
+import (
+	"fmt"
+	"strings"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const spotifyTrackURL = "https://open.spotify.com/track/"
+
+func init() { Register(func(Config) Platform { return spotify{} }) }
+
+// spotify is the source platform: songs are collected from Spotify, so its
+// IDs are trusted and never searched for.
+type spotify struct{}
+
+func (spotify) Key() string  { return models.PlatformSpotify }
+func (spotify) Name() string { return "Spotify" }
+
+func (spotify) ParseID(link string) string {
+	// Handle URL: https://open.spotify.com/track/ID?si=...
+	// Handle URI: spotify:track:ID
+	if strings.HasPrefix(link, "spotify:track:") {
+		return strings.TrimPrefix(link, "spotify:track:")
+	}
+	if strings.Contains(link, "/track/") {
+		parts := strings.Split(link, "/track/")
+		if len(parts) > 1 {
+			idPart := parts[1]
+			// Remove query params
+			if idx := strings.Index(idPart, "?"); idx != -1 {
+				return idPart[:idx]
+			}
+			return idPart
+		}
+	}
+	// Assume it might be just the ID if alphanumeric and length 22
+	if len(link) == 22 {
+		return link
+	}
+	return ""
+}
+
+func (spotify) URL(id string) string {
+	return spotifyTrackURL + id
+}
+
+func (spotify) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	if id == "" {
+		return models.Match{}, fmt.Errorf("missing spotify id")
+	}
+	return models.Match{Method: MatchSource, Score: 1}, nil
+}
+
+func (spotify) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	return "", models.Match{}, fmt.Errorf("%w: spotify is the source platform", ErrNoResults)
+}
+
+func (spotify) Encode(id string, p *codec.CardPayload) error {
+	p.Spotify = id
+	return nil
+}
+
+func (spotify) Decode(p codec.CardPayload) string {
+	return p.Spotify
+}
@@ -0,0 +1,113 @@
+package platform
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var tidalTrackPattern = regexp.MustCompile(`/track/(\d+)`)
+
+func init() { Register(func(c Config) Platform { return tidal{c} }) }
+
+// tidal IDs are numeric track IDs.
+type tidal struct{ Config }
+
+func (tidal) Key() string  { return models.PlatformTidal }
+func (tidal) Name() string { return "Tidal" }
+
+func (tidal) ParseID(link string) string {
+	// Expected: https://tidal.com/browse/track/<ID> or https://listen.tidal.com/track/<ID>
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return trackPathID(u.Path)
+}
+
+func (tidal) URL(id string) string {
+	return "https://tidal.com/browse/track/" + id
+}
+
+// Validate scores the track page; Tidal's public pages carry no ISRC.
+func (t tidal) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	if !isNumericID(id) {
+		return models.Match{}, fmt.Errorf("%w: malformed tidal id %q", ErrMismatch, id)
+	}
+	return t.matchSong(song, "", func() (float64, error) {
+		return pageScore(client, t.Upstream.Tidal+"/browse/track/"+id, song)
+	})
+}
+
+func (t tidal) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	trackIDs, err := t.search(client, song.Title, song.Artists[0])
+	if err != nil {
+		return "", models.Match{}, err
+	}
+
+	return bestCandidate(trackIDs[:min(len(trackIDs), maxCandidates)], func(id string) (models.Match, error) {
+		return t.Validate(client, id, song)
+	})
+}
+
+func (tidal) Encode(id string, p *codec.CardPayload) error {
+	p.Tidal = id
+	return nil
+}
+
+func (tidal) Decode(p codec.CardPayload) string {
+	return p.Tidal
+}
+
+// search returns the track IDs linked from the search page.
+func (t tidal) search(client *retryablehttp.Client, title, artist string) ([]string, error) {
+	term := fmt.Sprintf("%s %s", title, artist)
+	u, _ := url.Parse(t.Upstream.Tidal + "/search")
+	q := u.Query()
+	q.Set("q", term)
+	u.RawQuery = q.Encode()
+
+	req, _ := retryablehttp.NewRequest("GET", u.String(), nil)
+	req.Header.Set("User-Agent", browserUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := tidalTrackPattern.FindAllStringSubmatch(string(bodyBytes), 20)
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		id := match[1]
+		if !seen[id] {
+			ids = append(ids, id)
+			seen[id] = true
+		}
+		if len(ids) == 5 {
+			break
+		}
+	}
+
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	return nil, ErrNoResults
+}
@@ -0,0 +1,220 @@
+package platform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"temporalize/internal/match"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+	xhtml "golang.org/x/net/html"
+)
+
+var ErrLowScore = fmt.Errorf("%w: match score below threshold", ErrMismatch)
+
+// maxCandidates bounds how many search results are fetched and scored.
+const maxCandidates = 5
+
+// matchSong decides whether a platform track is the song: by ISRC when both
+// sides have one, otherwise by the text score, which must reach
+// MatchThreshold.
+func (c Config) matchSong(song *models.Song, isrc string, text func() (float64, error)) (models.Match, error) {
+	if song.ISRC != "" && isrc != "" {
+		if !strings.EqualFold(song.ISRC, isrc) {
+			return models.Match{}, fmt.Errorf("%w: got %s, want %s", ErrISRCMismatch, isrc, song.ISRC)
+		}
+		return models.Match{Method: MatchISRC, Score: 1}, nil
+	}
+	score, err := text()
+	if err != nil {
+		return models.Match{}, err
+	}
+	if score < c.MatchThreshold {
+		return models.Match{}, fmt.Errorf("%w: %.2f < %.2f", ErrLowScore, score, c.MatchThreshold)
+	}
+	return models.Match{Method: MatchText, Score: score}, nil
+}
+
+// bestCandidate matches each candidate ID and returns the best: an ISRC
+// match outright, otherwise the highest text score.
+func bestCandidate(ids []string, matchID func(id string) (models.Match, error)) (string, models.Match, error) {
+	type scored struct {
+		id string
+		m  models.Match
+	}
+	var ok []scored
+	for _, id := range ids {
+		m, err := matchID(id)
+		if err != nil {
+			continue
+		}
+		if m.Method == MatchISRC {
+			return id, m, nil
+		}
+		ok = append(ok, scored{id, m})
+	}
+	if len(ok) == 0 {
+		return "", models.Match{}, ErrNoResults
+	}
+	sort.SliceStable(ok, func(i, j int) bool { return ok[i].m.Score > ok[j].m.Score })
+	return ok[0].id, ok[0].m, nil
+}
+
+// textScore scores a candidate against the song: the lower of the title
+// score and the best score of any of the song's "/"-separated artists.
+// Artists are also looked for in the titles, as video titles often carry
+// them.
+func textScore(song *models.Song, titles, artists []string) float64 {
+	title := match.Best(song.Title, titles...)
+
+	artistCandidates := append(append([]string(nil), artists...), titles...)
+	artist := 0.0
+	for _, a := range strings.Split(song.Artists[0], "/") {
+		if s := match.Best(strings.TrimSpace(a), artistCandidates...); s > artist {
+			artist = s
+		}
+	}
+	return min(title, artist)
+}
+
+// metadataScore scores a title and artist reported by a platform API.
+func metadataScore(song *models.Song, gotTitle, gotArtist string) float64 {
+	return textScore(song, []string{gotTitle}, []string{gotArtist})
+}
+
+// pageScore fetches a page and scores the song against its <title> and
+// descriptive meta tags. Pages without any fall back to looking for the
+// title and artist anywhere in the text.
+func pageScore(client *retryablehttp.Client, url string, song *models.Song) (float64, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError(resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	doc, err := xhtml.Parse(bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	if texts := pageMetadata(doc); len(texts) > 0 {
+		return textScore(song, texts, texts), nil
+	}
+
+	text := " " + match.Normalize(pageText(doc)) + " "
+	contains := func(s string) bool {
+		n := match.Normalize(s)
+		return n != "" && strings.Contains(text, " "+n+" ")
+	}
+	if !contains(song.Title) {
+		return 0, fmt.Errorf("%w: title %q not found", ErrLowScore, song.Title)
+	}
+	for _, a := range strings.Split(song.Artists[0], "/") {
+		if contains(a) {
+			return 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: artist %q not found", ErrLowScore, song.Artists[0])
+}
+
+// statusError describes an unexpected HTTP status. Not-found answers mean
+// the ID is dead, anything else that it could not be checked.
+func statusError(code int) error {
+	if code == http.StatusNotFound || code == http.StatusGone {
+		return fmt.Errorf("%w: status %d", ErrMismatch, code)
+	}
+	return fmt.Errorf("status %d", code)
+}
+
+// metaNames are the meta tags that describe the page's track.
+var metaNames = map[string]bool{
+	"og:title":            true,
+	"og:description":      true,
+	"twitter:title":       true,
+	"twitter:description": true,
+	"description":         true,
+	"music:musician":      true,
+}
+
+func pageMetadata(doc *xhtml.Node) []string {
+	var texts []string
+	var f func(*xhtml.Node)
+	f = func(n *xhtml.Node) {
+		if n.Type == xhtml.ElementNode {
+			switch n.Data {
+			case "title":
+				if n.FirstChild != nil && n.FirstChild.Type == xhtml.TextNode {
+					texts = append(texts, n.FirstChild.Data)
+				}
+			case "meta":
+				name, content := "", ""
+				for _, a := range n.Attr {
+					switch a.Key {
+					case "name", "property":
+						name = a.Val
+					case "content":
+						content = a.Val
+					}
+				}
+				if metaNames[name] && strings.TrimSpace(content) != "" {
+					texts = append(texts, content)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return texts
+}
+
+func pageText(doc *xhtml.Node) string {
+	var b strings.Builder
+	var f func(*xhtml.Node)
+	f = func(n *xhtml.Node) {
+		if n.Type == xhtml.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return b.String()
+}
+
+// trackPathID returns the path segment after "/track/" in a URL path.
+func trackPathID(path string) string {
+	parts := strings.Split(path, "/track/")
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.Trim(parts[1], "/")
+}
+
+func isNumericID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package platform
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"temporalize/internal/fakes"
+	"temporalize/internal/match"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// fakePlatforms builds every platform against the fakes.
+func fakePlatforms(t *testing.T) (map[string]Platform, *fakes.Server, *retryablehttp.Client) {
+	t.Helper()
+	srv := fakes.New()
+	t.Cleanup(srv.Close)
+	platforms, err := Select("", Config{Upstream: srv.Endpoints(), MatchThreshold: match.DefaultThreshold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKey := make(map[string]Platform)
+	for _, p := range platforms {
+		byKey[p.Key()] = p
+	}
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+	return byKey, srv, client
+}
+
+func TestMatchSong(t *testing.T) {
+	cfg := Config{MatchThreshold: 0.8}
+	tests := []struct {
+		name     string
+		songISRC string
+		isrc     string
+		score    float64
+		want     models.Match
+		err      error
+	}{
+		{"isrc beats a poor text score", "GBAAA8500123", "gbaaa8500123", 0.1, models.Match{Method: MatchISRC, Score: 1}, nil},
+		{"isrc mismatch beats a perfect text score", "GBAAA8500123", "GBAAA8700999", 1, models.Match{}, ErrISRCMismatch},
+		{"text without a platform isrc", "GBAAA8500123", "", 0.9, models.Match{Method: MatchText, Score: 0.9}, nil},
+		{"text without a song isrc", "", "GBAAA8500123", 0.85, models.Match{Method: MatchText, Score: 0.85}, nil},
+		{"text below the threshold", "", "", 0.79, models.Match{}, ErrLowScore},
+	}
+	for _, tt := range tests {
+		song := &models.Song{ISRC: tt.songISRC}
+		scored := false
+		m, err := cfg.matchSong(song, tt.isrc, func() (float64, error) {
+			scored = true
+			return tt.score, nil
+		})
+		if m != tt.want || !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("%s: matchSong = %+v, %v, want %+v, %v", tt.name, m, err, tt.want, tt.err)
+		}
+		// Text is only scored when either side has no ISRC.
+		if wantScored := tt.songISRC == "" || tt.isrc == ""; scored != wantScored {
+			t.Errorf("%s: text scored = %v, want %v", tt.name, scored, wantScored)
+		}
+		if tt.err != nil && !Mismatch(err) {
+			t.Errorf("%s: %v is not a mismatch", tt.name, err)
+		}
+	}
+}
+
+func TestValidateISRC(t *testing.T) {
+	platforms, srv, client := fakePlatforms(t)
+	takeOnMe := func(isrc, title string) *models.Song {
+		return &models.Song{Title: title, Artists: []string{"a-ha"}, ISRC: isrc}
+	}
+	tests := []struct {
+		name     string
+		platform string
+		id       string
+		song     *models.Song
+		method   string
+		err      error
+	}{
+		{"deezer isrc", models.PlatformDeezer, "664107", takeOnMe("NOA018500011", "Take On Me"), MatchISRC, nil},
+		{"deezer isrc despite the title", models.PlatformDeezer, "664107", takeOnMe("NOA018500011", "Hunting High and Low"), MatchISRC, nil},
+		{"deezer isrc mismatch despite the title", models.PlatformDeezer, "664107", takeOnMe("GBAAA8500123", "Take On Me"), "", ErrISRCMismatch},
+		{"deezer text without isrc", models.PlatformDeezer, "664107", takeOnMe("", "Take On Me"), MatchText, nil},
+		{"deezer text mismatch without isrc", models.PlatformDeezer, "664107", takeOnMe("", "Hunting High and Low"), "", ErrLowScore},
+		{"apple isrc from the itunes lookup", models.PlatformAppleMusic, "1035048414:1035048822", takeOnMe("NOA018500011", "Take On Me"), MatchISRC, nil},
+		{"apple isrc mismatch", models.PlatformAppleMusic, "1035048414:1035048822", takeOnMe("GBAAA8500123", "Take On Me"), "", ErrISRCMismatch},
+		{"tidal text, it has no isrc", models.PlatformTidal, "1309291", takeOnMe("NOA018500011", "Take On Me"), MatchText, nil},
+		{"youtube text, it has no isrc", models.PlatformYoutubeMusic, "djV11Xbc914", takeOnMe("NOA018500011", "Take On Me"), MatchText, nil},
+	}
+	for _, tt := range tests {
+		before := len(srv.Requests())
+		m, err := platforms[tt.platform].Validate(client, tt.id, tt.song)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: Validate = %+v, %v, want %v", tt.name, m, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || m.Method != tt.method {
+			t.Errorf("%s: Validate = %+v, %v, want method %s", tt.name, m, err, tt.method)
+		}
+		if m.Method == MatchISRC && m.Score != 1 {
+			t.Errorf("%s: isrc match scored %v", tt.name, m.Score)
+		}
+		// An ISRC match never falls back to scoring the page.
+		if tt.platform == models.PlatformAppleMusic && m.Method == MatchISRC {
+			for _, r := range srv.Requests()[before:] {
+				if strings.HasPrefix(r, "/apple-music/") {
+					t.Errorf("%s: fetched %s despite the isrc", tt.name, r)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchPrefersISRC(t *testing.T) {
+	platforms, _, client := fakePlatforms(t)
+	apple := platforms[models.PlatformAppleMusic]
+	dontYou := func(isrc string) *models.Song {
+		return &models.Song{Title: "Don't You (Forget About Me)", Artists: []string{"Simple Minds"}, ISRC: isrc}
+	}
+
+	// The live version comes first in the results and scores well on text,
+	// but only the studio recording carries the song's ISRC.
+	id, m, err := apple.Search(client, dontYou("GBAAA8500123"))
+	if err != nil || id != "1440832700:1440832755" || m.Method != MatchISRC {
+		t.Errorf("Search with isrc = %q, %+v, %v, want the studio recording by isrc", id, m, err)
+	}
+	id, m, err = apple.Search(client, dontYou(""))
+	if err != nil || id != "1440832700:1440832755" || m.Method != MatchText || m.Score <= 0 {
+		t.Errorf("Search without isrc = %q, %+v, %v, want the studio recording by text", id, m, err)
+	}
+	if _, _, err := apple.Search(client, dontYou("GBAAA0000000")); !errors.Is(err, ErrNoResults) {
+		t.Errorf("Search with an isrc no result has = %v, want ErrNoResults", err)
+	}
+}
@@ -0,0 +1,135 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/models"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+var youtubeVideoIDPattern = regexp.MustCompile(`"videoId":"([a-zA-Z0-9_-]{11})"`)
+
+func init() { Register(func(c Config) Platform { return youtubeMusic{c} }) }
+
+// youtubeMusic IDs are video IDs.
+type youtubeMusic struct{ Config }
+
+func (youtubeMusic) Key() string  { return models.PlatformYoutubeMusic }
+func (youtubeMusic) Name() string { return "YouTube Music" }
+
+func (youtubeMusic) ParseID(link string) string {
+	// Expected: https://music.youtube.com/watch?v=<VideoID>
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("v")
+}
+
+func (youtubeMusic) URL(id string) string {
+	return "https://music.youtube.com/watch?v=" + id
+}
+
+// Validate scores the oEmbed title and author; YouTube exposes no ISRC.
+func (y youtubeMusic) Validate(client *retryablehttp.Client, id string, song *models.Song) (models.Match, error) {
+	if id == "" {
+		return models.Match{}, fmt.Errorf("missing youtube id")
+	}
+
+	u, _ := url.Parse(y.Upstream.YouTube + "/oembed")
+	q := u.Query()
+	q.Set("url", y.URL(id))
+	q.Set("format", "json")
+	u.RawQuery = q.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return models.Match{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return models.Match{}, statusError(resp.StatusCode)
+	}
+
+	var result struct {
+		Title      string `json:"title"`
+		AuthorName string `json:"author_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return models.Match{}, err
+	}
+
+	return y.matchSong(song, "", func() (float64, error) {
+		return metadataScore(song, result.Title, result.AuthorName), nil
+	})
+}
+
+func (y youtubeMusic) Search(client *retryablehttp.Client, song *models.Song) (string, models.Match, error) {
+	videoIDs, err := y.search(client, song.Title, song.Artists[0])
+	if err != nil {
+		return "", models.Match{}, err
+	}
+
+	return bestCandidate(videoIDs[:min(len(videoIDs), maxCandidates)], func(id string) (models.Match, error) {
+		return y.Validate(client, id, song)
+	})
+}
+
+func (youtubeMusic) Encode(id string, p *codec.CardPayload) error {
+	p.YouTube = id
+	return nil
+}
+
+func (youtubeMusic) Decode(p codec.CardPayload) string {
+	return p.YouTube
+}
+
+// search returns the video IDs on the results page.
+func (y youtubeMusic) search(client *retryablehttp.Client, title, artist string) ([]string, error) {
+	term := fmt.Sprintf("%s %s audio", title, artist)
+	u, _ := url.Parse(y.Upstream.YouTube + "/results")
+	q := u.Query()
+	q.Set("search_query", term)
+	u.RawQuery = q.Encode()
+
+	req, _ := http.NewRequest("GET", u.String(), nil)
+	req.Header.Set("User-Agent", browserUserAgent)
+
+	resp, err := client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := youtubeVideoIDPattern.FindAllStringSubmatch(string(bodyBytes), 10)
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		if len(match) > 1 {
+			id := match[1]
+			if !seen[id] {
+				ids = append(ids, id)
+				seen[id] = true
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	return nil, ErrNoResults
+}
@@ -0,0 +1,109 @@
+// Package policy decides which looked up songs are good enough to print,
+// from the validation status of their platform links.
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"temporalize/internal/models"
+	"temporalize/internal/platform"
+)
+
+// Default requires Spotify plus any two other platforms.
+const Default = "spotify+2"
+
+// Policy requires a link on each Required platform and on at least Others
+// of the remaining platforms.
+type Policy struct {
+	Required []string
+	Others   int
+	// AllowUnverified counts links that could not be checked.
+	AllowUnverified bool
+}
+
+// Parse parses a policy such as "spotify+2" or "spotify+appleMusic+1":
+// platform keys joined by "+", optionally ending in the number of other
+// platforms needed. "all" requires every platform.
+func Parse(s string) (Policy, error) {
+	var p Policy
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "all") {
+		p.Required = platform.Keys()
+		return p, nil
+	}
+	parts := strings.Split(s, "+")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if n, err := strconv.Atoi(part); err == nil {
+			if i != len(parts)-1 || n < 0 {
+				return Policy{}, fmt.Errorf("invalid policy %q: the count of other platforms must come last", s)
+			}
+			p.Others = n
+			continue
+		}
+		key, ok := lookupKey(part)
+		if !ok {
+			return Policy{}, fmt.Errorf("invalid policy %q: %w %q (known: %s)", s, platform.ErrUnknownPlatform, part, strings.Join(platform.Keys(), ", "))
+		}
+		p.Required = append(p.Required, key)
+	}
+	if others := len(platform.Keys()) - len(p.Required); p.Others > others {
+		return Policy{}, fmt.Errorf("invalid policy %q: only %d other platforms", s, others)
+	}
+	return p, nil
+}
+
+func lookupKey(s string) (string, bool) {
+	for _, key := range platform.Keys() {
+		if strings.EqualFold(key, s) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Check returns nil if the song satisfies the policy, otherwise an error
+// naming what is missing.
+func (p Policy) Check(g *models.GeneratedSong) error {
+	required := make(map[string]bool, len(p.Required))
+	var missing []string
+	for _, key := range p.Required {
+		required[key] = true
+		if !p.Usable(g, key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required %s", strings.Join(missing, ", "))
+	}
+
+	others := 0
+	for _, key := range platform.Keys() {
+		if !required[key] && p.Usable(g, key) {
+			others++
+		}
+	}
+	if others < p.Others {
+		return fmt.Errorf("only %d of %d other platforms", others, p.Others)
+	}
+	return nil
+}
+
+// Usable reports whether the song's link for key counts towards the
+// policy, and so may be printed on its card. Summaries written before
+// per-platform statuses fall back to the song's Invalid flag.
+func (p Policy) Usable(g *models.GeneratedSong, key string) bool {
+	if g.Link(key) == "" {
+		return false
+	}
+	if g.Platforms == nil {
+		return !g.Invalid
+	}
+	status, ok := g.Platforms[key]
+	if !ok {
+		return false
+	}
+	return status.Verified() || p.AllowUnverified && status.Status == models.StatusUnverified
+}
@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"testing"
+
+	"temporalize/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse("spotify+AppleMusic+1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Required) != 2 || p.Required[0] != models.PlatformSpotify || p.Required[1] != models.PlatformAppleMusic || p.Others != 1 {
+		t.Errorf("Parse = %+v", p)
+	}
+
+	for _, bad := range []string{"spotify+napster", "2+spotify", "spotify+9", "spotify+-1"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	song := func(statuses map[string]string) *models.GeneratedSong {
+		g := &models.GeneratedSong{}
+		for key, status := range statuses {
+			if status != models.StatusFailed {
+				g.SetLink(key, "https://example.com/"+key)
+			}
+			g.SetStatus(key, models.PlatformStatus{Status: status})
+		}
+		return g
+	}
+
+	p, err := Parse(Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		song     *models.GeneratedSong
+		unverify bool
+		pass     bool
+	}{
+		{"enough", song(map[string]string{"spotify": "ok", "deezer": "fixed", "tidal": "ok", "youtubeMusic": "failed"}), false, true},
+		{"too few", song(map[string]string{"spotify": "ok", "deezer": "ok", "tidal": "failed"}), false, false},
+		{"no spotify", song(map[string]string{"spotify": "failed", "deezer": "ok", "tidal": "ok", "appleMusic": "ok"}), false, false},
+		{"unverified", song(map[string]string{"spotify": "ok", "deezer": "ok", "tidal": "unverified"}), false, false},
+		{"unverified allowed", song(map[string]string{"spotify": "ok", "deezer": "ok", "tidal": "unverified"}), true, true},
+	}
+	for _, tt := range tests {
+		p.AllowUnverified = tt.unverify
+		if err := p.Check(tt.song); (err == nil) != tt.pass {
+			t.Errorf("%s: Check = %v, want pass %v", tt.name, err, tt.pass)
+		}
+	}
+
+	legacy := &models.GeneratedSong{Spotify: "s", Deezer: "d", Tidal: "t"}
+	if err := p.Check(legacy); err != nil {
+		t.Errorf("legacy valid song: %v", err)
+	}
+	legacy.Invalid = true
+	if err := p.Check(legacy); err == nil {
+		t.Error("legacy invalid song passed")
+	}
+}
@@ -0,0 +1,234 @@
+// Package ratelimit throttles outgoing HTTP requests per upstream host with
+// token buckets, and pauses a host for everyone once it answers with
+// Retry-After.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket refilling at Rate requests per second and holding
+// at most Burst tokens. A zero Rate does not limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// DefaultLimits are conservative limits for the services lookup talks to.
+// Odesli is the tightest; its unauthenticated API throttles long runs.
+var DefaultLimits = map[string]Limit{
+	"api.song.link":    {Rate: 1, Burst: 2},
+	"api.spotify.com":  {Rate: 10, Burst: 10},
+	"itunes.apple.com": {Rate: 0.5, Burst: 5},
+	"music.apple.com":  {Rate: 2, Burst: 4},
+	"www.amazon.com":   {Rate: 1, Burst: 2},
+	"music.amazon.com": {Rate: 2, Burst: 4},
+	"youtube.com":      {Rate: 2, Burst: 4},
+	"api.deezer.com":   {Rate: 8, Burst: 10},
+	"tidal.com":        {Rate: 2, Burst: 4},
+	"musicbrainz.org":  {Rate: 1, Burst: 1},
+}
+
+// now and sleep are replaced by tests.
+var (
+	now   = time.Now
+	sleep = func(ctx context.Context, d time.Duration) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+)
+
+// Limiter holds one token bucket per upstream host. Every transport
+// returned by Wrap draws from the same buckets.
+type Limiter struct {
+	// Limits maps a host to its limit. A host also matches its subdomains,
+	// which share the parent's bucket. Other hosts are not limited.
+	Limits map[string]Limit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New returns a Limiter using the default limits.
+func New() *Limiter {
+	limits := make(map[string]Limit, len(DefaultLimits))
+	for host, l := range DefaultLimits {
+		limits[host] = l
+	}
+	return &Limiter{Limits: limits}
+}
+
+// Wrap returns an http.RoundTripper that waits for a token from the request
+// host's bucket before passing the request to base. A nil base means
+// http.DefaultTransport.
+func (l *Limiter) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{limiter: l, base: base}
+}
+
+type transport struct {
+	limiter *Limiter
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := t.limiter.bucket(req.URL.Hostname())
+	if wait := b.reserve(now()); wait > 0 {
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		at := now()
+		if d, ok := retryAfter(resp.Header.Get("Retry-After"), at); ok {
+			b.pause(at.Add(d))
+		}
+	}
+	return resp, nil
+}
+
+// bucket returns the shared bucket for host, keyed by the most specific
+// configured host it falls under.
+func (l *Limiter) bucket(host string) *bucket {
+	key, limit := host, Limit{}
+	for h := host; h != ""; {
+		if hl, ok := l.Limits[h]; ok {
+			key, limit = h, hl
+			break
+		}
+		_, parent, found := strings.Cut(h, ".")
+		if !found {
+			break
+		}
+		h = parent
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*bucket)
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newBucket(limit)
+		l.buckets[key] = b
+	}
+	return b
+}
+
+type bucket struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+	// pausedUntil holds requests back after a Retry-After.
+	pausedUntil time.Time
+}
+
+func newBucket(l Limit) *bucket {
+	if l.Burst < 1 {
+		l.Burst = 1
+	}
+	return &bucket{limit: l, tokens: float64(l.Burst)}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. Tokens may go negative, which queues callers in arrival order.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+	if b.limit.Rate > 0 {
+		if !b.last.IsZero() {
+			b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+			if max := float64(b.limit.Burst); b.tokens > max {
+				b.tokens = max
+			}
+		}
+		b.last = now
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+		}
+	}
+	if paused := b.pausedUntil.Sub(now); paused > wait {
+		wait = paused
+	}
+	return wait
+}
+
+func (b *bucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at.Sub(now), at.After(now)
+	}
+	return 0, false
+}
+
+// ParseLimits parses a comma separated list of host=rate[:burst] overrides,
+// e.g. "api.song.link=0.5:1,www.youtube.com=5", into limits. Rate is in
+// requests per second; a rate of 0 removes the limit.
+func ParseLimits(list string, limits map[string]Limit) error {
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid rate limit %q, want host=rate[:burst]", entry)
+		}
+		rateStr, burstStr, hasBurst := strings.Cut(value, ":")
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 {
+			return fmt.Errorf("invalid rate for %s: %q", host, rateStr)
+		}
+		limit := Limit{Rate: rate, Burst: 1}
+		if hasBurst {
+			if limit.Burst, err = strconv.Atoi(burstStr); err != nil || limit.Burst < 1 {
+				return fmt.Errorf("invalid burst for %s: %q", host, burstStr)
+			}
+		}
+		limits[strings.TrimSpace(host)] = limit
+	}
+	return nil
+}
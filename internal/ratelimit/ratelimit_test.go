@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeClock stops time at t0 and records the waits instead of sleeping.
+func fakeClock(t *testing.T) (advance func(time.Duration), waits *[]time.Duration) {
+	t.Helper()
+	origNow, origSleep := now, sleep
+	t.Cleanup(func() { now, sleep = origNow, origSleep })
+	at := t0
+	now = func() time.Time { return at }
+	waits = new([]time.Duration)
+	sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return func(d time.Duration) { at = at.Add(d) }, waits
+}
+
+func TestBucket(t *testing.T) {
+	b := newBucket(Limit{Rate: 2, Burst: 2})
+	var got []time.Duration
+	for range 4 {
+		got = append(got, b.reserve(t0))
+	}
+	// The burst goes through, then callers queue half a second apart.
+	if want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("waits = %v, want %v", got, want)
+	}
+	// Idle time refills no more than the burst.
+	if wait := b.reserve(t0.Add(time.Minute)); wait != 0 {
+		t.Errorf("wait after a minute = %v", wait)
+	}
+	if wait := b.reserve(t0.Add(time.Minute)); wait != 0 {
+		t.Errorf("second wait after a minute = %v", wait)
+	}
+	if wait := b.reserve(t0.Add(time.Minute)); wait != 500*time.Millisecond {
+		t.Errorf("third wait after a minute = %v, want 500ms", wait)
+	}
+
+	unlimited := newBucket(Limit{})
+	for range 100 {
+		if wait := unlimited.reserve(t0); wait != 0 {
+			t.Fatalf("unlimited bucket waits %v", wait)
+		}
+	}
+	unlimited.pause(t0.Add(3 * time.Second))
+	unlimited.pause(t0.Add(time.Second))
+	if wait := unlimited.reserve(t0); wait != 3*time.Second {
+		t.Errorf("paused wait = %v, want the longer pause", wait)
+	}
+}
+
+func TestLimiterBuckets(t *testing.T) {
+	l := &Limiter{Limits: map[string]Limit{"youtube.com": {Rate: 2, Burst: 4}}}
+	if l.bucket("www.youtube.com") != l.bucket("youtube.com") {
+		t.Error("subdomain does not share its parent's bucket")
+	}
+	if b := l.bucket("music.youtube.com"); b.limit != (Limit{Rate: 2, Burst: 4}) {
+		t.Errorf("subdomain limit = %+v", b.limit)
+	}
+	if b := l.bucket("example.com"); b.limit.Rate != 0 || b == l.bucket("youtube.com") {
+		t.Errorf("unlisted host limit = %+v", b.limit)
+	}
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	advance, waits := fakeClock(t)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/seconds":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/date":
+			w.Header().Set("Retry-After", t0.Add(time.Minute).Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: (&Limiter{}).Wrap(srv.Client().Transport)}
+	get := func(path string) int {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := get("/seconds"); status != http.StatusTooManyRequests {
+		t.Fatalf("status = %d", status)
+	}
+	// The throttled answer is passed on, and the host is paused for
+	// everyone until Retry-After has passed.
+	get("/")
+	advance(10 * time.Second)
+	get("/")
+	advance(20 * time.Second)
+	get("/")
+	if want := []time.Duration{30 * time.Second, 20 * time.Second}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+
+	*waits = nil
+	get("/date")
+	get("/")
+	if want := []time.Duration{time.Minute - 30*time.Second}; !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits after an HTTP date = %v, want %v", *waits, want)
+	}
+	if requests != 6 {
+		t.Errorf("server saw %d requests, want 6", requests)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{" 5 ", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{t0.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{t0.Add(-time.Hour).Format(http.TimeFormat), 0, false},
+		{"soon", 0, false},
+	} {
+		got, ok := retryAfter(tt.value, t0)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits := map[string]Limit{"api.song.link": {Rate: 1, Burst: 2}, "tidal.com": {Rate: 2, Burst: 4}}
+	if err := ParseLimits(" api.song.link=0.5:1, www.youtube.com=5,,tidal.com=0", limits); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Limit{
+		"api.song.link":   {Rate: 0.5, Burst: 1},
+		"www.youtube.com": {Rate: 5, Burst: 1},
+		"tidal.com":       {Rate: 0, Burst: 1},
+	}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("limits = %v, want %v", limits, want)
+	}
+
+	for _, list := range []string{"api.song.link", "api.song.link=fast", "api.song.link=-1", "api.song.link=1:0", "api.song.link=1:x"} {
+		if err := ParseLimits(list, map[string]Limit{}); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded", list)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"temporalize/internal/codec"
+	"temporalize/internal/platform"
+)
+
+// maxDecodeBody bounds a decode request; a QR code holds under 3KB.
+const maxDecodeBody = 8 << 10
+
+// DecodeRequest is the body of POST /api/decode.
+type DecodeRequest struct {
+	// Payload is the raw bytes of the scanned QR code in base64, standard
+	// or URL safe, padding optional.
+	Payload string `json:"payload"`
+}
+
+// DecodeResponse describes a card: its payload format version, explicit
+// flag and a link per platform on it, in registry order.
+type DecodeResponse struct {
+	Version  int          `json:"version"`
+	Explicit bool         `json:"explicit"`
+	Links    []DecodeLink `json:"links"`
+}
+
+// DecodeLink is a platform's compact ID and canonical listening URL.
+type DecodeLink struct {
+	Platform string `json:"platform"`
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// decode serves POST /api/decode with the Go codec, so other clients need
+// no decoder of their own.
+func decode(w http.ResponseWriter, r *http.Request) {
+	var req DecodeRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDecodeBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, apiError{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if req.Payload == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "invalid request: payload is empty"})
+		return
+	}
+	data, err := codec.DecodeBase64(req.Payload)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("payload is not base64: %v", err)})
+		return
+	}
+	var p codec.CardPayload
+	if err := p.Decode(data); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, apiError{Error: fmt.Sprintf("payload is not a card: %v", err)})
+		return
+	}
+
+	resp := DecodeResponse{Version: p.Version, Explicit: p.Explicit, Links: []DecodeLink{}}
+	for _, pl := range platform.All() {
+		if id := pl.Decode(p); id != "" {
+			resp.Links = append(resp.Links, DecodeLink{Platform: pl.Key(), Name: pl.Name(), ID: id, URL: pl.URL(id)})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
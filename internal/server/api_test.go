@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"temporalize/internal/codec"
+)
+
+func post(t *testing.T, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/decode", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	testHandler().ServeHTTP(rec, req)
+	var v map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("response %q is not JSON: %v", rec.Body, err)
+	}
+	return rec, v
+}
+
+func TestDecode(t *testing.T) {
+	data, err := codec.CardPayload{Explicit: true, Spotify: "2WfaOiMkCvy7F5fcp2zZ8L", Deezer: "3135556"}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawURLEncoding} {
+		rec, _ := post(t, `{"payload": "`+enc.EncodeToString(data)+`"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var resp DecodeResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		want := DecodeResponse{
+			Version:  codec.CurrentVersion,
+			Explicit: true,
+			Links: []DecodeLink{
+				{Platform: "deezer", Name: "Deezer", ID: "3135556", URL: "https://www.deezer.com/track/3135556"},
+				{Platform: "spotify", Name: "Spotify", ID: "2WfaOiMkCvy7F5fcp2zZ8L", URL: "https://open.spotify.com/track/2WfaOiMkCvy7F5fcp2zZ8L"},
+			},
+		}
+		if !reflect.DeepEqual(resp, want) {
+			t.Errorf("response = %+v, want %+v", resp, want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		body   string
+		status int
+		want   string
+	}{
+		{`{"payload": `, http.StatusBadRequest, "invalid request"},
+		{`{"data": "AA=="}`, http.StatusBadRequest, `unknown field "data"`},
+		{`{}`, http.StatusBadRequest, "payload is empty"},
+		{`{"payload": "not base64!"}`, http.StatusBadRequest, "payload is not base64"},
+		{`{"payload": "IA=="}`, http.StatusUnprocessableEntity, "payload is not a card"},
+		{`{"payload": "` + strings.Repeat("A", maxDecodeBody) + `"}`, http.StatusRequestEntityTooLarge, "invalid request"},
+	}
+	for _, tt := range tests {
+		rec, v := post(t, tt.body)
+		if rec.Code != tt.status {
+			t.Errorf("%.40s: status = %d, want %d", tt.body, rec.Code, tt.status)
+		}
+		if msg, _ := v["error"].(string); !strings.Contains(msg, tt.want) {
+			t.Errorf("%.40s: error = %q, want %q", tt.body, msg, tt.want)
+		}
+	}
+}
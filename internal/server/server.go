@@ -0,0 +1,185 @@
+// Package server serves the scanner web app over HTTP or HTTPS, with a
+// health check for the deployment's probes.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"temporalize/web"
+)
+
+// Options configure the server. HTTPS needs either Dev or CertFile and
+// KeyFile; with neither, as behind the Kubernetes ingress, it serves HTTP.
+type Options struct {
+	Addr string
+	// Dir serves the web app from disk instead of the copy embedded in the
+	// binary, so edits show without rebuilding.
+	Dir string
+	// Dev serves HTTPS with a self-signed certificate, kept in CertDir so
+	// browsers remember the exception across restarts.
+	Dev     bool
+	CertDir string
+	// CertFile and KeyFile are PEM files of a real certificate.
+	CertFile string
+	KeyFile  string
+}
+
+// Run serves until interrupted, then finishes the requests in flight.
+func Run(opts Options) error {
+	files, err := webFiles(opts.Dir)
+	if err != nil {
+		return err
+	}
+	handler := Handler(files)
+	srv := &http.Server{Addr: opts.Addr, ReadHeaderTimeout: 10 * time.Second}
+
+	var listen func() error
+	switch {
+	case opts.Dev:
+		cert, err := devCertificate(opts.CertDir)
+		if err != nil {
+			return fmt.Errorf("dev certificate: %w", err)
+		}
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		listen = func() error { return srv.ListenAndServeTLS("", "") }
+		fmt.Printf("Starting HTTPS server on %s with a self-signed certificate...\n", opts.Addr)
+		fmt.Println("Note: You will see a security warning. Click 'Advanced' -> 'Proceed' to continue.")
+	case opts.CertFile != "":
+		handler = strictTransport(handler)
+		listen = func() error { return srv.ListenAndServeTLS(opts.CertFile, opts.KeyFile) }
+		fmt.Printf("Starting HTTPS server on %s...\n", opts.Addr)
+	default:
+		listen = srv.ListenAndServe
+		fmt.Printf("Starting HTTP server on %s...\n", opts.Addr)
+	}
+	srv.Handler = handler
+
+	errc := make(chan error, 1)
+	go func() { errc <- listen() }()
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	select {
+	case err := <-errc:
+		return err
+	case <-stop:
+	}
+	fmt.Println("Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// webFiles returns the web app in dir, or the embedded one when dir is
+// empty, checking that its scripts have been compiled.
+func webFiles(dir string) (fs.FS, error) {
+	var files fs.FS = web.FS
+	where := "the embedded web app; run go generate ./web and build with -tags webapp"
+	if dir != "" {
+		files = os.DirFS(dir)
+		where = dir + "; run go generate ./web"
+	}
+	for _, name := range []string{"decode.js", "app.js"} {
+		if _, err := fs.Stat(files, name); err != nil {
+			return nil, fmt.Errorf("%s is missing from %s", name, where)
+		}
+	}
+	return files, nil
+}
+
+// Handler serves the web app in files, the decode API and the health
+// check.
+func Handler(files fs.FS) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("POST /api/decode", decode)
+	mux.Handle("GET /", static{files: files, server: http.FileServerFS(files)})
+	return securityHeaders(mux)
+}
+
+// static serves files with validators and cache headers. Embedded files
+// have no modification time, so every file gets an ETag of its content.
+type static struct {
+	files  fs.FS
+	server http.Handler
+}
+
+func (s static) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+	f, err := s.files.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	// No directory listings.
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`"%x"`, h.Sum(nil)[:12]))
+
+	// The page and script keep their names between releases, so browsers
+	// revalidate them; icons rarely change.
+	if strings.HasPrefix(name, "icons/") || name == "favicon.ico" {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	s.server.ServeHTTP(w, r)
+}
+
+// contentSecurityPolicy allows the jsQR script and the Lobster font the page
+// loads from CDNs, and the inline styles and handlers it uses.
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"media-src 'self' blob:; " +
+	"connect-src 'self'; " +
+	"frame-ancestors 'none'"
+
+func securityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", contentSecurityPolicy)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Permissions-Policy", "camera=(self), microphone=(), geolocation=()")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// strictTransport pins browsers to HTTPS. Only a real certificate sets it:
+// a self-signed one would lock localhost out of plain HTTP.
+func strictTransport(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+		next.ServeHTTP(w, r)
+	})
+}
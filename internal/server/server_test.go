@@ -0,0 +1,132 @@
+package server
+
+import (
+	"crypto/x509"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"temporalize/web"
+)
+
+func testHandler() http.Handler {
+	return Handler(fstest.MapFS{
+		"index.html":      {Data: []byte("<h1>Temporalize</h1>")},
+		"app.js":          {Data: []byte("startScanner()")},
+		"icons/tidal.png": {Data: []byte("\x89PNG")},
+	})
+}
+
+func get(h http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	h := testHandler()
+	tests := []struct {
+		method, target string
+		status         int
+		cache          string
+		body           string
+	}{
+		{"GET", "/", 200, "no-cache", "<h1>Temporalize</h1>"},
+		{"GET", "/app.js", 200, "no-cache", "startScanner()"},
+		{"HEAD", "/app.js", 200, "no-cache", ""},
+		{"GET", "/icons/tidal.png", 200, "public, max-age=86400", "\x89PNG"},
+		{"GET", "/healthz", 200, "no-store", "ok\n"},
+		{"GET", "/icons/", 404, "", ""},
+		{"GET", "/missing.js", 404, "", ""},
+		{"POST", "/", 405, "", ""},
+	}
+	for _, tt := range tests {
+		rec := get(h, tt.method, tt.target, nil)
+		if rec.Code != tt.status {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+			continue
+		}
+		if got := rec.Header().Get("Cache-Control"); tt.cache != "" && got != tt.cache {
+			t.Errorf("%s %s Cache-Control = %q, want %q", tt.method, tt.target, got, tt.cache)
+		}
+		if tt.status == 200 && rec.Body.String() != tt.body {
+			t.Errorf("%s %s body = %q, want %q", tt.method, tt.target, rec.Body, tt.body)
+		}
+		for _, header := range []string{"Content-Security-Policy", "X-Content-Type-Options", "X-Frame-Options", "Referrer-Policy"} {
+			if rec.Header().Get(header) == "" {
+				t.Errorf("%s %s has no %s header", tt.method, tt.target, header)
+			}
+		}
+		if rec.Header().Get("Strict-Transport-Security") != "" {
+			t.Errorf("%s %s sets HSTS without a real certificate", tt.method, tt.target)
+		}
+	}
+}
+
+func TestHandlerRevalidates(t *testing.T) {
+	h := testHandler()
+	etag := get(h, "GET", "/app.js", nil).Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if rec := get(h, "GET", "/app.js", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Errorf("conditional GET = %d, want 304", rec.Code)
+	}
+	if other := get(h, "GET", "/", nil).Header().Get("ETag"); other == etag {
+		t.Errorf("index.html and app.js share ETag %s", etag)
+	}
+}
+
+func TestWebFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := webFiles(dir); err == nil || !strings.Contains(err.Error(), "decode.js is missing") {
+		t.Errorf("webFiles of an empty directory = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "decode.js"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webFiles(dir); err == nil || !strings.Contains(err.Error(), "app.js is missing") {
+		t.Errorf("webFiles without app.js = %v", err)
+	}
+
+	// Without -tags webapp the embedded app has no scripts.
+	if _, err := fs.Stat(web.FS, "app.js"); err != nil {
+		if _, err := webFiles(""); err == nil || !strings.Contains(err.Error(), "-tags webapp") {
+			t.Errorf("webFiles of the embedded app without scripts = %v", err)
+		}
+	}
+}
+
+func TestDevCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cert, err := devCertificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		if err := leaf.VerifyHostname(host); err != nil {
+			t.Errorf("certificate does not cover %s: %v", host, err)
+		}
+	}
+
+	again, err := devCertificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again.Certificate[0]) != string(cert.Certificate[0]) {
+		t.Error("devCertificate did not reuse the saved certificate")
+	}
+}
@@ -0,0 +1,274 @@
+// Package sheets imposes card faces onto printer paper: every page of
+// fronts is followed by a page of the matching backs, placed so that they
+// line up when the sheet is printed duplex, with crop marks along the cut
+// lines.
+package sheets
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"temporalize/internal/pdf"
+)
+
+// Paper is a sheet size in inches.
+type Paper struct {
+	Width  float64
+	Height float64
+}
+
+// Papers are the supported sheet sizes, by name.
+var Papers = map[string]Paper{
+	"letter": {Width: 8.5, Height: 11},
+	"a4":     {Width: 210 / 25.4, Height: 297 / 25.4},
+}
+
+// ParsePaper returns the paper called name.
+func ParsePaper(name string) (Paper, error) {
+	p, ok := Papers[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(Papers))
+		for n := range Papers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Paper{}, fmt.Errorf("unknown paper %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// Duplex is the edge a printed sheet is flipped over.
+type Duplex string
+
+const (
+	// LongEdge flips portrait sheets left to right, so backs are mirrored
+	// by column.
+	LongEdge Duplex = "long"
+	// ShortEdge flips portrait sheets top to bottom, so backs are mirrored
+	// by row.
+	ShortEdge Duplex = "short"
+)
+
+// ParseDuplex returns the duplex mode called s.
+func ParseDuplex(s string) (Duplex, error) {
+	switch d := Duplex(strings.ToLower(s)); d {
+	case LongEdge, ShortEdge:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown duplex %q, want %s or %s", s, LongEdge, ShortEdge)
+}
+
+// Options describe the sheets and the cards on them. Lengths are in
+// inches.
+type Options struct {
+	Paper  Paper
+	Duplex Duplex
+	// Width and Height are the trimmed card size. Card images also
+	// include Bleed on every side.
+	Width  float64
+	Height float64
+	Bleed  float64
+	// Registration adds a test sheet marking every card position on both
+	// sides, to check how a printer aligns duplex pages.
+	Registration bool
+}
+
+// Card is a card's front and back image files.
+type Card struct {
+	Front string
+	Back  string
+}
+
+const (
+	// minMargin is kept clear around the cards for the bleed and crop
+	// marks.
+	minMargin = 0.25
+	markGap   = 1.0 / 16
+	markMax   = 0.25
+	lineWidth = 0.25
+)
+
+// Layout is the grid of cards on a sheet. Neighbouring cards share their
+// cut lines, so only the bleed around the outside of the grid is printed.
+type Layout struct {
+	Cols, Rows int
+	opts       Options
+	// left and top are the grid's offset from the sheet's top left corner.
+	left, top float64
+}
+
+// NewLayout fits as many cards on a sheet as the paper allows, centred so
+// that fronts and backs line up.
+func NewLayout(opts Options) (Layout, error) {
+	l := Layout{
+		Cols: int((opts.Paper.Width - 2*minMargin) / opts.Width),
+		Rows: int((opts.Paper.Height - 2*minMargin) / opts.Height),
+		opts: opts,
+	}
+	if l.Cols < 1 || l.Rows < 1 {
+		return Layout{}, fmt.Errorf("a %vx%v card does not fit on a %.2fx%.2f sheet", opts.Width, opts.Height, opts.Paper.Width, opts.Paper.Height)
+	}
+	l.left = (opts.Paper.Width - float64(l.Cols)*opts.Width) / 2
+	l.top = (opts.Paper.Height - float64(l.Rows)*opts.Height) / 2
+	return l, nil
+}
+
+// PerSheet is the number of cards on a sheet.
+func (l Layout) PerSheet() int {
+	return l.Cols * l.Rows
+}
+
+// BackCell returns where the back of the card at col, row goes on the back
+// page.
+func (l Layout) BackCell(col, row int) (int, int) {
+	if l.opts.Duplex == ShortEdge {
+		return col, l.Rows - 1 - row
+	}
+	return l.Cols - 1 - col, row
+}
+
+// Write imposes cards onto sheets and writes them to w as a PDF, fronts
+// and backs on alternating pages. It returns the number of sheets.
+func Write(w io.Writer, opts Options, cards []Card) (int, error) {
+	l, err := NewLayout(opts)
+	if err != nil {
+		return 0, err
+	}
+	doc := pdf.NewWriter(w)
+	sheets := 0
+	if opts.Registration {
+		l.registration(doc)
+		sheets++
+	}
+
+	for start := 0; start < len(cards); start += l.PerSheet() {
+		sheet := cards[start:min(start+l.PerSheet(), len(cards))]
+
+		front := l.newPage(doc)
+		for i, c := range sheet {
+			if err := l.place(front, c.Front, i%l.Cols, i/l.Cols); err != nil {
+				return 0, err
+			}
+		}
+		back := l.newPage(doc)
+		for i, c := range sheet {
+			col, row := l.BackCell(i%l.Cols, i/l.Cols)
+			if err := l.place(back, c.Back, col, row); err != nil {
+				return 0, err
+			}
+		}
+		sheets++
+	}
+	return sheets, doc.Close()
+}
+
+// newPage starts a page with the crop marks drawn.
+func (l Layout) newPage(doc *pdf.Writer) *pdf.Page {
+	p := doc.NewPage(l.opts.Paper.Width*pdf.PointsPerInch, l.opts.Paper.Height*pdf.PointsPerInch)
+	bleed := l.opts.Bleed
+	right := l.left + float64(l.Cols)*l.opts.Width
+	bottom := l.top + float64(l.Rows)*l.opts.Height
+
+	// Marks run from just outside the bleed towards the paper edge.
+	hLen := min(markMax, l.left-bleed-markGap)
+	vLen := min(markMax, l.top-bleed-markGap)
+	if vLen > 0 {
+		for i := 0; i <= l.Cols; i++ {
+			x := l.left + float64(i)*l.opts.Width
+			l.line(p, x, l.top-bleed-markGap, x, l.top-bleed-markGap-vLen)
+			l.line(p, x, bottom+bleed+markGap, x, bottom+bleed+markGap+vLen)
+		}
+	}
+	if hLen > 0 {
+		for i := 0; i <= l.Rows; i++ {
+			y := l.top + float64(i)*l.opts.Height
+			l.line(p, l.left-bleed-markGap, y, l.left-bleed-markGap-hLen, y)
+			l.line(p, right+bleed+markGap, y, right+bleed+markGap+hLen, y)
+		}
+	}
+	return p
+}
+
+// place draws the image file at path into a cell, keeping its bleed only
+// on the outside of the grid.
+func (l Layout) place(p *pdf.Page, path string, col, row int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	bleed := l.opts.Bleed
+	x, y := l.cell(col, row)
+	dst := l.rect(x-bleed, y-bleed, l.opts.Width+2*bleed, l.opts.Height+2*bleed)
+
+	cx, cy, cw, ch := x, y, l.opts.Width, l.opts.Height
+	if col == 0 {
+		cx, cw = cx-bleed, cw+bleed
+	}
+	if col == l.Cols-1 {
+		cw += bleed
+	}
+	if row == 0 {
+		cy, ch = cy-bleed, ch+bleed
+	}
+	if row == l.Rows-1 {
+		ch += bleed
+	}
+	p.Image(img, dst, l.rect(cx, cy, cw, ch))
+	return nil
+}
+
+// registration writes a front and back page outlining every card with a
+// cross at its centre and its number. Held up to the light, the numbers
+// and crosses of a well aligned printout coincide.
+func (l Layout) registration(doc *pdf.Writer) {
+	for _, side := range []string{"front", "back"} {
+		p := l.newPage(doc)
+		for i := 0; i < l.PerSheet(); i++ {
+			col, row := i%l.Cols, i/l.Cols
+			if side == "back" {
+				col, row = l.BackCell(col, row)
+			}
+			x, y := l.cell(col, row)
+			p.Rect(l.rect(x, y, l.opts.Width, l.opts.Height), lineWidth)
+			cx, cy := x+l.opts.Width/2, y+l.opts.Height/2
+			l.line(p, cx-0.25, cy, cx+0.25, cy)
+			l.line(p, cx, cy-0.25, cx, cy+0.25)
+			p.Text((cx+0.05)*pdf.PointsPerInch, (l.opts.Paper.Height-cy+0.05)*pdf.PointsPerInch, 12, fmt.Sprint(i+1))
+		}
+		p.Text(l.left*pdf.PointsPerInch, (l.opts.Paper.Height-l.top+0.02)*pdf.PointsPerInch, 7,
+			fmt.Sprintf("Registration test, %s, %s edge duplex", side, l.opts.Duplex))
+	}
+}
+
+// cell returns the top left corner of a card's trim box.
+func (l Layout) cell(col, row int) (float64, float64) {
+	return l.left + float64(col)*l.opts.Width, l.top + float64(row)*l.opts.Height
+}
+
+// rect converts a box in inches from the top left of the sheet to PDF
+// points.
+func (l Layout) rect(x, y, w, h float64) pdf.Rect {
+	return pdf.Rect{
+		X: x * pdf.PointsPerInch,
+		Y: (l.opts.Paper.Height - y - h) * pdf.PointsPerInch,
+		W: w * pdf.PointsPerInch,
+		H: h * pdf.PointsPerInch,
+	}
+}
+
+// line strokes a line between two points in inches from the top left.
+func (l Layout) line(p *pdf.Page, x1, y1, x2, y2 float64) {
+	h := l.opts.Paper.Height
+	p.Line(x1*pdf.PointsPerInch, (h-y1)*pdf.PointsPerInch, x2*pdf.PointsPerInch, (h-y2)*pdf.PointsPerInch, lineWidth)
+}
@@ -0,0 +1,135 @@
+package sheets
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		paper      string
+		w, h       float64
+		cols, rows int
+	}{
+		{"letter", 2.5, 3.5, 3, 3},
+		{"a4", 2.5, 3.5, 3, 3},
+		{"letter", 1.625, 2.5, 4, 4},
+		{"a4", 1.625, 2.5, 4, 4},
+	}
+	for _, tt := range tests {
+		paper, err := ParsePaper(tt.paper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := NewLayout(Options{Paper: paper, Width: tt.w, Height: tt.h, Bleed: 0.125})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Cols != tt.cols || l.Rows != tt.rows {
+			t.Errorf("%s %vx%v: %dx%d cards, want %dx%d", tt.paper, tt.w, tt.h, l.Cols, l.Rows, tt.cols, tt.rows)
+		}
+	}
+
+	if _, err := NewLayout(Options{Paper: Papers["letter"], Width: 9, Height: 3}); err == nil {
+		t.Error("NewLayout fitted a card wider than the paper")
+	}
+	if _, err := ParsePaper("a3"); err == nil {
+		t.Error("ParsePaper(a3) succeeded")
+	}
+	if _, err := ParseDuplex("sideways"); err == nil {
+		t.Error("ParseDuplex(sideways) succeeded")
+	}
+}
+
+func TestBackCell(t *testing.T) {
+	l, err := NewLayout(Options{Paper: Papers["letter"], Duplex: LongEdge, Width: 2.5, Height: 3.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if col, row := l.BackCell(0, 1); col != 2 || row != 1 {
+		t.Errorf("long edge BackCell(0, 1) = %d, %d, want 2, 1", col, row)
+	}
+	l.opts.Duplex = ShortEdge
+	if col, row := l.BackCell(0, 0); col != 0 || row != 2 {
+		t.Errorf("short edge BackCell(0, 0) = %d, %d, want 0, 2", col, row)
+	}
+
+	// Fronts and backs of mirrored cells coincide when the sheet is flipped.
+	for _, d := range []Duplex{LongEdge, ShortEdge} {
+		l.opts.Duplex = d
+		for i := 0; i < l.PerSheet(); i++ {
+			col, row := l.BackCell(i%l.Cols, i/l.Cols)
+			fx, fy := l.cell(i%l.Cols, i/l.Cols)
+			bx, by := l.cell(col, row)
+			if d == LongEdge {
+				bx = l.opts.Paper.Width - bx - l.opts.Width
+			} else {
+				by = l.opts.Paper.Height - by - l.opts.Height
+			}
+			if abs(fx-bx) > 1e-9 || abs(fy-by) > 1e-9 {
+				t.Errorf("%s edge card %d: front at %v,%v, back at %v,%v", d, i, fx, fy, bx, by)
+			}
+		}
+	}
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "face.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := image.NewRGBA(image.Rect(0, 0, 8, 11))
+	draw.Draw(face, face.Bounds(), image.White, image.Point{}, draw.Src)
+	if err := png.Encode(f, face); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cards := make([]Card, 10)
+	for i := range cards {
+		cards[i] = Card{Front: path, Back: path}
+	}
+	var buf bytes.Buffer
+	opts := Options{Paper: Papers["letter"], Duplex: LongEdge, Width: 2.5, Height: 3.5, Bleed: 0.125, Registration: true}
+	n, err := Write(&buf, opts, cards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A registration sheet, then nine cards and one.
+	if n != 3 {
+		t.Errorf("Write = %d sheets, want 3", n)
+	}
+	doc := buf.String()
+	if !strings.Contains(doc, "/Count 6") {
+		t.Error("document does not have 6 pages")
+	}
+	if got := strings.Count(doc, "/Subtype /Image"); got != 20 {
+		t.Errorf("document has %d images, want 20", got)
+	}
+
+	buf.Reset()
+	opts.Registration = false
+	if n, err := Write(&buf, opts, cards); err != nil || n != 2 || !strings.Contains(buf.String(), "/Count 4") {
+		t.Errorf("Write without registration = %d, %v, want 2 sheets on 4 pages", n, err)
+	}
+
+	cards[3].Back = filepath.Join(dir, "missing.png")
+	if _, err := Write(&buf, opts, cards); err == nil {
+		t.Error("Write succeeded with a missing back")
+	}
+}
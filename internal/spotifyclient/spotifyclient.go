@@ -0,0 +1,69 @@
+// Package spotifyclient builds the Spotify Web API client shared by the
+// commands, authenticated with client credentials from the environment.
+package spotifyclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"temporalize/internal/endpoints"
+
+	"github.com/zmb3/spotify/v2"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+var ErrMissingCredentials = errors.New("missing SPOTIFY_CLIENT_ID or SPOTIFY_CLIENT_SECRET environment variables")
+
+// Credentials identify the Spotify app the commands run as.
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// FromEnv reads SPOTIFY_CLIENT_ID and SPOTIFY_CLIENT_SECRET.
+func FromEnv() Credentials {
+	return Credentials{
+		ClientID:     os.Getenv("SPOTIFY_CLIENT_ID"),
+		ClientSecret: os.Getenv("SPOTIFY_CLIENT_SECRET"),
+	}
+}
+
+// Check returns ErrMissingCredentials unless both values are set.
+func (c Credentials) Check() error {
+	if c.ClientID == "" || c.ClientSecret == "" {
+		return ErrMissingCredentials
+	}
+	return nil
+}
+
+// Options tune the transports under the client. Nil fields use
+// http.DefaultTransport unchanged.
+type Options struct {
+	// TokenTransport carries token requests and, beneath the OAuth
+	// transport, API requests that reach the network.
+	TokenTransport http.RoundTripper
+	// Wrap is applied on top of the OAuth transport, so anything it answers
+	// itself, such as a cache hit, needs no token.
+	Wrap func(http.RoundTripper) http.RoundTripper
+}
+
+// New returns a client for upstream's Spotify API.
+func New(ctx context.Context, creds Credentials, upstream endpoints.Endpoints, opts Options) *spotify.Client {
+	config := &clientcredentials.Config{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		TokenURL:     upstream.SpotifyToken,
+	}
+	if opts.TokenTransport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: opts.TokenTransport})
+	}
+	httpClient := config.Client(ctx)
+	if opts.Wrap != nil {
+		httpClient.Transport = opts.Wrap(httpClient.Transport)
+	}
+	// zmb3/spotify/v2 retries rate limited requests itself
+	return spotify.New(httpClient, spotify.WithRetry(true), spotify.WithBaseURL(upstream.SpotifyAPI))
+}
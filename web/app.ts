@@ -17,14 +17,11 @@ const ICONS = {
     spotify: '<img src="icons/spotify.png" class="icon" alt="Spotify">',
     apple: '<img src="icons/applemusic.png" class="icon" alt="Apple Music">',
     amazon: '<img src="icons/amazonmusic.png" class="icon" alt="Amazon Music">',
-    youtube: '<img src="icons/youtubemusic.png" class="icon" alt="YouTube Music">'
+    youtube: '<img src="icons/youtubemusic.png" class="icon" alt="YouTube Music">',
+    deezer: '<img src="icons/deezer.png" class="icon" alt="Deezer">',
+    tidal: '<img src="icons/tidal.png" class="icon" alt="Tidal">'
 };
 
-// Alphabets for Decompression
-const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz";
-const base36Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ";
-const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_";
-
 function reset() {
     stopScanner();
     resultDiv.style.display = 'none';
@@ -164,153 +161,6 @@ function tick() {
     requestAnimationFrame(tick);
 }
 
-// --- Decompression Logic ---
-
-interface DecodedData {
-    explicit: boolean;
-    amazonAlbum: string;
-    amazonTrack: string;
-    appleAlbum: string;
-    appleTrack: string;
-    spotify: string;
-    youtube: string;
-}
-
-// BigInt polyfill-ish for BaseN decoding
-function decodeBaseN(s: string, alphabet: string): bigint {
-    let val = 0n;
-    const base = BigInt(alphabet.length);
-    
-    for (let i = 0; i < s.length; i++) {
-        const char = s[i];
-        const idx = alphabet.indexOf(char);
-        if (idx === -1) throw new Error(`Invalid char ${char}`);
-        val = val * base + BigInt(idx);
-    }
-    return val;
-}
-
-function encodeBaseN(val: bigint, alphabet: string): string {
-    if (val === 0n) return alphabet[0];
-    let res = "";
-    const base = BigInt(alphabet.length);
-    while (val > 0n) {
-        const mod = val % base;
-        res = alphabet[Number(mod)] + res;
-        val = val / base;
-    }
-    return res;
-}
-
-function padString(s: string, length: number, padChar: string): string {
-    while (s.length < length) {
-        s = padChar + s;
-    }
-    return s;
-}
-
-function bytesToBigInt(bytes: number[]): bigint {
-    let val = 0n;
-    for (const b of bytes) {
-        val = (val << 8n) | BigInt(b);
-    }
-    return val;
-}
-
-function readUvarint(bytes: number[], offset: number): { val: bigint, n: number } {
-    let x = 0n;
-    let s = 0n;
-    for (let i = 0; ; i++) {
-        if (offset + i >= bytes.length) throw new Error("buffer overflow");
-        const b = BigInt(bytes[offset + i]);
-        if (b < 0x80n) {
-            x |= b << s;
-            return { val: x, n: i + 1 };
-        }
-        x |= (b & 0x7fn) << s;
-        s += 7n;
-    }
-}
-
-function readVarint(bytes: number[], offset: number): { val: bigint, n: number } {
-    const { val: ux, n } = readUvarint(bytes, offset);
-    let x = ux >> 1n;
-    if ((ux & 1n) !== 0n) {
-        x = ~x;
-    }
-    return { val: x, n };
-}
-
-function decompress(data: number[]): DecodedData {
-    if (data.length < 7) throw new Error("short data");
-    
-    let idx = 0;
-    
-    // Amazon Album + Explicit (7 bytes)
-    const amzAlbBytes = data.slice(idx, idx + 7);
-    idx += 7;
-    
-    // Extract Explicit
-    const explicit = (amzAlbBytes[0] & 0x80) !== 0;
-    // Clear Explicit bit
-    amzAlbBytes[0] &= 0x7F;
-    
-    let amazonAlbum = "";
-    const amzAlbVal = bytesToBigInt(amzAlbBytes);
-    if (amzAlbVal > 0n) {
-        amazonAlbum = padString(encodeBaseN(amzAlbVal, base36Chars), 10, base36Chars[0]);
-    }
-    
-    // Amazon Track (7 bytes)
-    if (idx + 7 > data.length) throw new Error("short data amz trk");
-    const amzTrkBytes = data.slice(idx, idx + 7);
-    idx += 7;
-    let amazonTrack = "";
-    const amzTrkVal = bytesToBigInt(amzTrkBytes);
-    if (amzTrkVal > 0n) {
-        amazonTrack = padString(encodeBaseN(amzTrkVal, base36Chars), 10, base36Chars[0]);
-    }
-    
-    // Apple Album (Uvarint)
-    const { val: appAlbVal, n: n1 } = readUvarint(data, idx);
-    idx += n1;
-    let appleAlbum = "";
-    if (appAlbVal > 0n) {
-        appleAlbum = appAlbVal.toString();
-    }
-    
-    // Apple Track (Varint Delta)
-    const { val: delta, n: n2 } = readVarint(data, idx);
-    idx += n2;
-    let appleTrack = "";
-    const appTrkVal = BigInt(appleAlbum || 0) + delta;
-    if (appTrkVal > 0n) {
-        appleTrack = appTrkVal.toString();
-    }
-    
-    // Spotify (17 bytes)
-    if (idx + 17 > data.length) throw new Error("short data spot");
-    const spotBytes = data.slice(idx, idx + 17);
-    idx += 17;
-    let spotify = "";
-    const spotVal = bytesToBigInt(spotBytes);
-    if (spotVal > 0n) {
-        spotify = padString(encodeBaseN(spotVal, base62Chars), 22, base62Chars[0]);
-    }
-    
-    // YouTube (9 bytes)
-    if (idx + 9 > data.length) throw new Error("short data yt");
-    const ytBytes = data.slice(idx, idx + 9);
-    idx += 9;
-    let youtube = "";
-    const ytVal = bytesToBigInt(ytBytes);
-    if (ytVal > 0n) {
-        youtube = padString(encodeBaseN(ytVal, base64Chars), 11, base64Chars[0]);
-    }
-    
-    return { explicit, amazonAlbum, amazonTrack, appleAlbum, appleTrack, spotify, youtube };
-}
-
 interface PlatformLink {
     platform: string;
     link: string;
@@ -347,6 +197,20 @@ function getAllLinks(decoded: DecodedData): PlatformLink[] {
             link: `https://music.youtube.com/watch?v=${decoded.youtube}`
         });
     }
+
+    if (decoded.deezer) {
+        links.push({
+            platform: 'deezer',
+            link: `https://www.deezer.com/track/${decoded.deezer}`
+        });
+    }
+
+    if (decoded.tidal) {
+        links.push({
+            platform: 'tidal',
+            link: `https://tidal.com/browse/track/${decoded.tidal}`
+        });
+    }
     
     return links;
 }
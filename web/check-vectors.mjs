@@ -0,0 +1,40 @@
+// Checks the compiled web decoder (decode.js) against the Go codec's golden
+// vectors. Run it with `task test:web`, which compiles the TypeScript first.
+import { readFileSync } from 'node:fs';
+import vm from 'node:vm';
+
+const decoder = {};
+vm.runInNewContext(readFileSync(new URL('decode.js', import.meta.url), 'utf8'), decoder);
+const vectors = JSON.parse(readFileSync(new URL('../internal/codec/testdata/vectors.json', import.meta.url), 'utf8'));
+
+let failed = 0;
+for (const v of vectors) {
+    const bytes = (v.payload.match(/../g) ?? []).map(b => parseInt(b, 16));
+    let got;
+    try {
+        got = decoder.decompress(bytes);
+    } catch (e) {
+        if (v.decoded !== null) {
+            console.error(`${v.name}: ${e.message}`);
+            failed++;
+        }
+        continue;
+    }
+    if (v.decoded === null) {
+        console.error(`${v.name}: decoded to ${JSON.stringify(got)}, want an error`);
+        failed++;
+        continue;
+    }
+    for (const [field, want] of Object.entries(v.decoded)) {
+        if (got[field] !== want) {
+            console.error(`${v.name}: ${field} = ${JSON.stringify(got[field])}, want ${JSON.stringify(want)}`);
+            failed++;
+        }
+    }
+}
+
+if (failed > 0) {
+    console.error(`${failed} mismatches against ${vectors.length} vectors`);
+    process.exit(1);
+}
+console.log(`${vectors.length} vectors ok`);
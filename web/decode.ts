@@ -0,0 +1,245 @@
+// Card payload decoding, kept free of the DOM so it can be checked against
+// the Go codec's golden vectors (internal/codec/testdata/vectors.json) with
+// `task test:web`. Keep the two in step: a format change in one fails that
+// check until the other follows.
+
+// Alphabets for Decompression
+const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz";
+const base36Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ";
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_";
+
+interface DecodedData {
+    version: number;
+    explicit: boolean;
+    amazonAlbum: string;
+    amazonTrack: string;
+    appleAlbum: string;
+    appleTrack: string;
+    spotify: string;
+    youtube: string;
+    deezer: string;
+    tidal: string;
+}
+
+// BigInt polyfill-ish for BaseN decoding
+function decodeBaseN(s: string, alphabet: string): bigint {
+    let val = 0n;
+    const base = BigInt(alphabet.length);
+    
+    for (let i = 0; i < s.length; i++) {
+        const char = s[i];
+        const idx = alphabet.indexOf(char);
+        if (idx === -1) throw new Error(`Invalid char ${char}`);
+        val = val * base + BigInt(idx);
+    }
+    return val;
+}
+
+function encodeBaseN(val: bigint, alphabet: string): string {
+    if (val === 0n) return alphabet[0];
+    let res = "";
+    const base = BigInt(alphabet.length);
+    while (val > 0n) {
+        const mod = val % base;
+        res = alphabet[Number(mod)] + res;
+        val = val / base;
+    }
+    return res;
+}
+
+function padString(s: string, length: number, padChar: string): string {
+    while (s.length < length) {
+        s = padChar + s;
+    }
+    return s;
+}
+
+function bytesToBigInt(bytes: number[]): bigint {
+    let val = 0n;
+    for (const b of bytes) {
+        val = (val << 8n) | BigInt(b);
+    }
+    return val;
+}
+
+function readUvarint(bytes: number[], offset: number): { val: bigint, n: number } {
+    let x = 0n;
+    let s = 0n;
+    for (let i = 0; ; i++) {
+        if (offset + i >= bytes.length) throw new Error("buffer overflow");
+        const b = BigInt(bytes[offset + i]);
+        if (b < 0x80n) {
+            x |= b << s;
+            return { val: x, n: i + 1 };
+        }
+        x |= (b & 0x7fn) << s;
+        s += 7n;
+    }
+}
+
+function readVarint(bytes: number[], offset: number): { val: bigint, n: number } {
+    const { val: ux, n } = readUvarint(bytes, offset);
+    let x = ux >> 1n;
+    if ((ux & 1n) !== 0n) {
+        x = ~x;
+    }
+    return { val: x, n };
+}
+
+function decodeFixed(bytes: number[], alphabet: string, width: number): string {
+    const val = bytesToBigInt(bytes);
+    if (val === 0n) return "";
+    return padString(encodeBaseN(val, alphabet), width, alphabet[0]);
+}
+
+function readFixed(data: number[], idx: number, size: number, name: string): number[] {
+    if (idx + size > data.length) throw new Error(`short data ${name}`);
+    return data.slice(idx, idx + size);
+}
+
+// Reads the Apple album (Uvarint) and track (Varint delta) IDs.
+function readApple(data: number[], idx: number): { appleAlbum: string, appleTrack: string, n: number } {
+    const { val: appAlbVal, n: n1 } = readUvarint(data, idx);
+    const { val: delta, n: n2 } = readVarint(data, idx + n1);
+    const appTrkVal = appAlbVal + delta;
+    if (appTrkVal < 0n) throw new Error("bad apple track delta");
+    return {
+        appleAlbum: appAlbVal > 0n ? appAlbVal.toString() : "",
+        appleTrack: appTrkVal > 0n ? appTrkVal.toString() : "",
+        n: n1 + n2,
+    };
+}
+
+// Versioned payloads keep the format version in bits 6-4 of the first byte.
+// Legacy (v1) cards always have zeros there.
+function decompress(data: number[]): DecodedData {
+    if (data.length === 0) throw new Error("short data");
+    const version = (data[0] >> 4) & 0x7;
+    switch (version) {
+        case 0:
+            return decompressV1(data);
+        case 2:
+            return decompressV2(data);
+        default:
+            throw new Error(`unsupported payload version ${version}`);
+    }
+}
+
+function decompressV1(data: number[]): DecodedData {
+    if (data.length < 7) throw new Error("short data");
+    
+    let idx = 0;
+    
+    // Amazon Album + Explicit (7 bytes)
+    const amzAlbBytes = data.slice(idx, idx + 7);
+    idx += 7;
+    
+    // Extract Explicit
+    const explicit = (amzAlbBytes[0] & 0x80) !== 0;
+    // Clear Explicit bit
+    amzAlbBytes[0] &= 0x7F;
+    const amazonAlbum = decodeFixed(amzAlbBytes, base36Chars, 10);
+    
+    // Amazon Track (7 bytes)
+    const amazonTrack = decodeFixed(readFixed(data, idx, 7, "amz trk"), base36Chars, 10);
+    idx += 7;
+    
+    // Apple Album (Uvarint) + Apple Track (Varint Delta)
+    const apple = readApple(data, idx);
+    idx += apple.n;
+    
+    // Spotify (17 bytes)
+    const spotify = decodeFixed(readFixed(data, idx, 17, "spot"), base62Chars, 22);
+    idx += 17;
+    
+    // YouTube (9 bytes)
+    const youtube = decodeFixed(readFixed(data, idx, 9, "yt"), base64Chars, 11);
+    
+    return {
+        version: 1,
+        explicit,
+        amazonAlbum,
+        amazonTrack,
+        appleAlbum: apple.appleAlbum,
+        appleTrack: apple.appleTrack,
+        spotify,
+        youtube,
+        deezer: "",
+        tidal: "",
+    };
+}
+
+// Presence bitmap bits for v2 payloads, in field order.
+const HAS_SPOTIFY = 1n << 0n;
+const HAS_APPLE = 1n << 1n;
+const HAS_AMAZON = 1n << 2n;
+const HAS_YOUTUBE = 1n << 3n;
+const HAS_DEEZER = 1n << 4n;
+const HAS_TIDAL = 1n << 5n;
+const KNOWN_FIELDS = HAS_SPOTIFY | HAS_APPLE | HAS_AMAZON | HAS_YOUTUBE | HAS_DEEZER | HAS_TIDAL;
+const FLAG_AMAZON_SAME_TRACK = 0x01;
+
+function decompressV2(data: number[]): DecodedData {
+    const header = data[0];
+    const explicit = (header & 0x80) !== 0;
+    const flags = header & 0x0f;
+    if ((flags & ~FLAG_AMAZON_SAME_TRACK) !== 0) throw new Error("unknown header flags");
+
+    const { val: present, n } = readUvarint(data, 1);
+    if ((present & ~KNOWN_FIELDS) !== 0n) throw new Error("unknown fields");
+    let idx = 1 + n;
+
+    const decoded: DecodedData = {
+        version: 2,
+        explicit,
+        amazonAlbum: "",
+        amazonTrack: "",
+        appleAlbum: "",
+        appleTrack: "",
+        spotify: "",
+        youtube: "",
+        deezer: "",
+        tidal: "",
+    };
+
+    if (present & HAS_SPOTIFY) {
+        decoded.spotify = decodeFixed(readFixed(data, idx, 17, "spot"), base62Chars, 22);
+        idx += 17;
+    }
+
+    if (present & HAS_APPLE) {
+        const apple = readApple(data, idx);
+        decoded.appleAlbum = apple.appleAlbum;
+        decoded.appleTrack = apple.appleTrack;
+        idx += apple.n;
+    }
+
+    if (present & HAS_AMAZON) {
+        decoded.amazonAlbum = decodeFixed(readFixed(data, idx, 7, "amz alb"), base36Chars, 10);
+        idx += 7;
+        if (flags & FLAG_AMAZON_SAME_TRACK) {
+            decoded.amazonTrack = decoded.amazonAlbum;
+        } else {
+            decoded.amazonTrack = decodeFixed(readFixed(data, idx, 7, "amz trk"), base36Chars, 10);
+            idx += 7;
+        }
+    }
+
+    if (present & HAS_YOUTUBE) {
+        decoded.youtube = decodeFixed(readFixed(data, idx, 9, "yt"), base64Chars, 11);
+        idx += 9;
+    }
+
+    if (present & HAS_DEEZER) {
+        const { val, n } = readUvarint(data, idx);
+        decoded.deezer = val > 0n ? val.toString() : "";
+        idx += n;
+    }
+
+    if (present & HAS_TIDAL) {
+        const { val } = readUvarint(data, idx);
+        decoded.tidal = val > 0n ? val.toString() : "";
+    }
+
+    return decoded;
+}
@@ -0,0 +1,10 @@
+//go:build webapp
+
+package web
+
+import "embed"
+
+// FS holds the page, its icons and the compiled scripts.
+//
+//go:embed index.html favicon.ico icons app.js decode.js
+var FS embed.FS
@@ -0,0 +1,10 @@
+//go:build !webapp
+
+package web
+
+import "embed"
+
+// FS holds the page and its icons; the scripts need -tags webapp.
+//
+//go:embed index.html favicon.ico icons
+var FS embed.FS
@@ -0,0 +1,7 @@
+// Package web embeds the scanner web app. app.js and decode.js are compiled
+// from their .ts sources by go generate (tsc) and embedded only when
+// building with -tags webapp; a plain build embeds the page without them
+// and the server reports them missing.
+package web
+
+//go:generate npx tsc decode.ts app.ts --target es2020